@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Upper bound of the value of any solution: optimum of the LP relaxation, which takes items
+// in order of value density and a fraction of the first one that doesn't fit.
+// Constraints only remove solutions, so the bound holds with them too. Values of curved
+// categories are taken at their highest factor and all positive synergy bonuses are added.
+func upperBound(items []Item, params Params) float64 {
+	values := make([]float64, len(items))
+	for i, item := range items {
+		values[i] = float64(item.Value)
+		if curve, ok := params.Curves[item.Category]; ok {
+			highest := 0.0
+			for _, point := range curve {
+				highest = math.Max(highest, point.Factor)
+			}
+			values[i] *= highest
+		}
+	}
+
+	// Locked items are in every solution whatever their density
+	bound, capacity := 0.0, params.MaxWeight
+	locked := make([]bool, len(items))
+	for _, i := range params.Locked {
+		if !locked[i] {
+			locked[i] = true
+			bound += values[i]
+			capacity -= items[i].Weight
+		}
+	}
+	var order []int
+	for i := range items {
+		if !locked[i] && values[i] > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]]*items[order[b]].Weight > values[order[b]]*items[order[a]].Weight
+	})
+	for _, i := range order {
+		if capacity <= 0 && items[i].Weight > 0 {
+			break
+		}
+		if items[i].Weight <= capacity {
+			bound += values[i]
+			capacity -= items[i].Weight
+		} else {
+			bound += values[i] * capacity / items[i].Weight
+			capacity = 0
+		}
+	}
+	return bound + float64(maxSynergyBonus(params.Synergies))
+}
+
+// Share of the bound value falls short of, 0 for bounds of no value
+func boundGap(value int, bound float64) float64 {
+	if bound <= 0 {
+		return 0
+	}
+	return math.Max(0, (bound-float64(value))/bound)
+}
+
+// Print how far result is from the upper bound at most
+func showBound(w io.Writer, bound float64, result Result) {
+	fmt.Fprintf(w, "\nUpper bound: %.2f\n", bound)
+	if result.Violation > 0 {
+		fmt.Fprintf(w, "Soft constraints are violated, value is not comparable with the bound\n")
+		return
+	}
+	fmt.Fprintf(w, "Solution is within %.2f%% of the bound\n", 100*boundGap(result.Value, bound))
+}
+
+// Print whether result reached the known optimum of the instance
+func showKnownOptimum(w io.Writer, optimum int, result Result) {
+	if result.Violation == 0 && result.Value >= optimum {
+		fmt.Fprintf(w, "Known optimum %d found after %d iterations\n", optimum, result.BestIteration)
+		return
+	}
+	fmt.Fprintf(w, "Known optimum %d missed by %d (%.2f%%)\n", optimum, optimum-result.Value,
+		100*float64(optimum-result.Value)/float64(optimum))
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"container/list"
+	"math"
+)
+
+// LRU cache of evaluated solutions, keyed by solution packed into a bitset
+type evalCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	// Most recently used entries are in the front
+	order *list.List
+
+	hits, misses int
+}
+
+type cacheEntry struct {
+	key    string
+	value  int
+	weight float64
+}
+
+// Creating cache holding at most capacity solutions
+func newEvalCache(capacity int) *evalCache {
+	return &evalCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Packing solution into a string with one bit per item
+func solutionKey(solution []int) string {
+	key := make([]byte, (len(solution)+7)/8)
+	for i, included := range solution {
+		if included == 1 {
+			key[i/8] |= 1 << (i % 8)
+		}
+	}
+	return string(key)
+}
+
+// Looking up value and weight of previously evaluated solution
+func (c *evalCache) get(key string) (value int, weight float64, ok bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return 0, 0, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	entry := element.Value.(*cacheEntry)
+	return entry.value, entry.weight, true
+}
+
+// Storing evaluated solution, evicting the least recently used one if cache is full
+func (c *evalCache) put(key string, value int, weight float64) {
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, weight: weight})
+}
+
+// Evaluating solutions of one instance, optionally through the cache
+type evaluator struct {
+	items []Item
+	cache *evalCache
+	// Summing weights with compensated summation
+	compensated bool
+	synergies   []Synergy
+	capacity    float64
+
+	// Solution evaluated last with its totals, next solution is evaluated by the items
+	// it differs in. Consecutive solutions of the solvers differ by a few items only.
+	last   []int
+	value  int
+	weight neumaierSum
+	// Curved category of every item, nil for items of linear value
+	categoryOf []*curvedCategory
+	categories []*curvedCategory
+	synergy    *synergyIndex
+}
+
+// Creating evaluator for params, cache size of zero disables caching
+func newEvaluator(items []Item, params Params) *evaluator {
+	e := &evaluator{items: items, compensated: params.CompensatedSum, synergies: params.Synergies,
+		capacity: params.MaxWeight}
+	if len(params.Curves) > 0 {
+		sizes := map[string]int{}
+		for _, item := range items {
+			sizes[item.Category]++
+		}
+		byName := map[string]*curvedCategory{}
+		for _, category := range sortedCategories(params.Curves) {
+			byName[category] = newCurvedCategory(params.Curves[category], sizes[category])
+			e.categories = append(e.categories, byName[category])
+		}
+		e.categoryOf = make([]*curvedCategory, len(items))
+		for i, item := range items {
+			e.categoryOf[i] = byName[item.Category]
+		}
+	}
+	if len(params.Synergies) > 0 {
+		e.synergy = newSynergyIndex(items, params.Synergies)
+	}
+	if params.CacheSize > 0 {
+		e.cache = newEvalCache(params.CacheSize)
+	}
+	return e
+}
+
+func (e *evaluator) compute(solution []int) (int, float64) {
+	value, weight := e.update(solution)
+	if e.synergy != nil {
+		value += e.synergy.bonus
+	}
+	return value, weight
+}
+
+// Updating totals of the last solution by the items solution differs in. Items of curved
+// categories change only the totals of their categories, the rest is linear. Running weight
+// is compensated, so that adding and removing items doesn't drift, but weights near
+// capacity are summed again the way the full evaluation sums them, so that a solution
+// fits or not regardless of the solutions evaluated before it.
+func (e *evaluator) update(solution []int) (int, float64) {
+	if len(e.last) != len(solution) {
+		e.last = make([]int, len(solution))
+		e.value, e.weight = 0, neumaierSum{}
+		for _, category := range e.categories {
+			category.values, category.total = category.values[:0], 0
+		}
+		if e.synergy != nil {
+			e.synergy.reset()
+		}
+	}
+	for i, included := range solution {
+		if included == e.last[i] {
+			continue
+		}
+		var category *curvedCategory
+		if e.categoryOf != nil {
+			category = e.categoryOf[i]
+		}
+		switch {
+		case included == 1 && category != nil:
+			category.add(e.items[i].Value)
+			e.weight.add(e.items[i].Weight)
+		case included == 1:
+			e.value += e.items[i].Value
+			e.weight.add(e.items[i].Weight)
+		case e.last[i] == 1 && category != nil:
+			category.remove(e.items[i].Value)
+			e.weight.add(-e.items[i].Weight)
+		case e.last[i] == 1:
+			e.value -= e.items[i].Value
+			e.weight.add(-e.items[i].Weight)
+		}
+		if e.synergy != nil {
+			switch {
+			case included == 1:
+				e.synergy.add(i)
+			case e.last[i] == 1:
+				e.synergy.remove(i)
+			}
+		}
+		e.last[i] = included
+	}
+	value := e.value
+	if e.categories != nil {
+		total := float64(e.value)
+		for _, category := range e.categories {
+			total += category.sum()
+		}
+		value = int(math.Round(total))
+	}
+
+	weight := e.weight.value()
+	if math.Abs(weight-e.capacity) < 1e-6*math.Max(1, e.capacity) {
+		if e.compensated {
+			_, weight = computeEnergyCompensated(solution, e.items)
+		} else {
+			_, weight = computeEnergy(solution, e.items)
+		}
+	}
+	return value, weight
+}
+
+// Calculating total value and total weight of solution
+func (e *evaluator) evaluate(solution []int) (int, float64) {
+	if e.cache == nil {
+		return e.compute(solution)
+	}
+
+	key := solutionKey(solution)
+	if value, weight, ok := e.cache.get(key); ok {
+		return value, weight
+	}
+	value, weight := e.compute(solution)
+	e.cache.put(key, value, weight)
+	return value, weight
+}
@@ -0,0 +1,64 @@
+package main
+
+import "fmt"
+
+// Number of selected items
+func selectedCount(solution []int) int {
+	count := 0
+	for _, included := range solution {
+		count += included
+	}
+	return count
+}
+
+// Cap on the number of selected items, like parcels per courier trip
+type itemCountLimit struct {
+	Max int
+}
+
+func (c itemCountLimit) Name() string {
+	return fmt.Sprintf("items <= %d", c.Max)
+}
+
+func (c itemCountLimit) Satisfied(solution []int, items []Item) bool {
+	return selectedCount(solution) <= c.Max
+}
+
+// Least number of selected items. Search starts from few items, so it's soft.
+type itemCountMinimum struct {
+	Min int
+}
+
+func (c itemCountMinimum) Name() string {
+	return fmt.Sprintf("items >= %d", c.Min)
+}
+
+func (c itemCountMinimum) Satisfied(solution []int, items []Item) bool {
+	return selectedCount(solution) >= c.Min
+}
+
+// Share of the items missing to the minimum
+func (c itemCountMinimum) Violation(solution []int, items []Item) float64 {
+	if missing := c.Min - selectedCount(solution); missing > 0 {
+		return float64(missing) / float64(c.Min)
+	}
+	return 0
+}
+
+// Cardinality constraints, 0 disables the bound
+func cardinalityConstraints(minItems, maxItems int) ([]Constraint, error) {
+	if minItems < 0 || maxItems < 0 {
+		return nil, fmt.Errorf("number of items must not be negative, got %d and %d", minItems, maxItems)
+	}
+	if maxItems > 0 && minItems > maxItems {
+		return nil, fmt.Errorf("min items %d is over max items %d", minItems, maxItems)
+	}
+	var constraints []Constraint
+	if maxItems > 0 {
+		constraints = append(constraints, itemCountLimit{Max: maxItems})
+	}
+	if minItems > 0 {
+		constraints = append(constraints, itemCountMinimum{Min: minItems})
+	}
+	return constraints, nil
+}
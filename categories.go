@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits of the selected items of a category besides the overall capacity, 0 leaves a limit out
+type CategoryLimit struct {
+	Weight float64 `json:"weight,omitempty"`
+	Count  int     `json:"count,omitempty"`
+}
+
+// Weight and count limits of a category, like "at most 2 kg of electronics"
+type categoryLimit struct {
+	Category string
+	CategoryLimit
+}
+
+func (c categoryLimit) Name() string {
+	var limits []string
+	if c.Weight > 0 {
+		limits = append(limits, fmt.Sprintf("weight <= %g", c.Weight))
+	}
+	if c.Count > 0 {
+		limits = append(limits, fmt.Sprintf("count <= %d", c.Count))
+	}
+	return fmt.Sprintf("%s %s", c.Category, strings.Join(limits, ", "))
+}
+
+func (c categoryLimit) Satisfied(solution []int, items []Item) bool {
+	weight, count := categoryUsage(solution, items, c.Category)
+	return (c.Weight <= 0 || weight <= c.Weight) && (c.Count <= 0 || count <= c.Count)
+}
+
+// Total weight and number of selected items of category
+func categoryUsage(solution []int, items []Item, category string) (float64, int) {
+	var weight neumaierSum
+	count := 0
+	for i, included := range solution {
+		if included == 1 && items[i].Category == category {
+			weight.add(items[i].Weight)
+			count++
+		}
+	}
+	return weight.value(), count
+}
+
+// Categories having limits in sorted order, for stable output
+func sortedLimitCategories(limits map[string]CategoryLimit) []string {
+	categories := make([]string, 0, len(limits))
+	for category := range limits {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Limits of categories sorted by category name, categories without limits are left out
+func categoryConstraints(limits map[string]CategoryLimit) []Constraint {
+	var constraints []Constraint
+	for _, category := range sortedLimitCategories(limits) {
+		if limit := limits[category]; limit.Weight > 0 || limit.Count > 0 {
+			constraints = append(constraints, categoryLimit{Category: category, CategoryLimit: limit})
+		}
+	}
+	return constraints
+}
+
+// Parsing comma separated category limits like "electronics=2,books=5" into limits,
+// as weights or as counts of items
+func parseCategoryLimits(spec string, counts bool, limits map[string]CategoryLimit) error {
+	for _, field := range splitNames(spec) {
+		category, value, ok := strings.Cut(field, "=")
+		category = strings.TrimSpace(category)
+		if !ok || category == "" {
+			return fmt.Errorf("category limit must be category=limit, got %q", field)
+		}
+		limit := limits[category]
+		if counts {
+			count, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || count < 0 {
+				return fmt.Errorf("count limit of category %s must be a non-negative whole number, got %q", category, value)
+			}
+			limit.Count = count
+		} else {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || math.IsNaN(weight) || weight < 0 {
+				return fmt.Errorf("weight limit of category %s must be a non-negative number, got %q", category, value)
+			}
+			limit.Weight = weight
+		}
+		limits[category] = limit
+	}
+	return nil
+}
+
+// Print weight and count of every limited category against its limits
+func showCategoryLimits(w io.Writer, solution []int, items []Item, constraints []Constraint) {
+	first := true
+	for _, c := range constraints {
+		if limit, ok := c.(categoryLimit); ok {
+			if first {
+				fmt.Fprintln(w)
+				first = false
+			}
+			weight, count := categoryUsage(solution, items, limit.Category)
+			fmt.Fprintf(w, "Category %s: weight %s", limit.Category, formatFloat(weight))
+			if limit.Weight > 0 {
+				fmt.Fprintf(w, " of %s", formatFloat(limit.Weight))
+			}
+			fmt.Fprintf(w, ", %d items", count)
+			if limit.Count > 0 {
+				fmt.Fprintf(w, " of %d", limit.Count)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
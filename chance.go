@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Mean and variance of the total weight of selected items, weights of items with
+// standard deviation are normally distributed around their weight
+func weightDistribution(solution []int, items []Item) (mean, variance float64) {
+	var m, v neumaierSum
+	for i, included := range solution {
+		if included == 1 {
+			m.add(items[i].Weight)
+			v.add(items[i].WeightSD * items[i].WeightSD)
+		}
+	}
+	return m.value(), v.value()
+}
+
+// Probability that the total weight of selected items exceeds capacity
+func overflowProbability(solution []int, items []Item, capacity float64) float64 {
+	mean, variance := weightDistribution(solution, items)
+	if variance == 0 {
+		if mean > capacity {
+			return 1
+		}
+		return 0
+	}
+	return 0.5 * math.Erfc((capacity-mean)/math.Sqrt(2*variance))
+}
+
+// Cap on the probability that uncertain weights of selected items exceed the capacity,
+// as weight estimates of shipments routinely overflow a plan by their means
+type chanceLimit struct {
+	Capacity    float64
+	Probability float64
+}
+
+func (c chanceLimit) Name() string {
+	return fmt.Sprintf("P(weight > %g) <= %g", c.Capacity, c.Probability)
+}
+
+// Mean weight plus its quantile margin fits, the same as the probability staying under the limit
+func (c chanceLimit) Satisfied(solution []int, items []Item) bool {
+	mean, variance := weightDistribution(solution, items)
+	return mean+math.Sqrt(variance)*math.Sqrt2*math.Erfinv(1-2*c.Probability) <= c.Capacity
+}
+
+// Chance constraint of capacity, probability 0 disables it
+func chanceConstraints(capacity, probability float64) ([]Constraint, error) {
+	if probability == 0 {
+		return nil, nil
+	}
+	if math.IsNaN(probability) || probability <= 0 || probability >= 1 {
+		return nil, fmt.Errorf("overflow probability must be between 0 and 1, got %v", probability)
+	}
+	return []Constraint{chanceLimit{Capacity: capacity, Probability: probability}}, nil
+}
+
+// Print probability of overflowing the capacity against its limit
+func showOverflow(w io.Writer, solution []int, items []Item, constraints []Constraint) {
+	for _, c := range constraints {
+		if limit, ok := c.(chanceLimit); ok {
+			fmt.Fprintf(w, "\nOverflow probability: %.2f%% (limit %.2f%%)\n",
+				100*overflowProbability(solution, items, limit.Capacity), 100*limit.Probability)
+		}
+	}
+}
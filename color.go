@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI color codes of text output
+const (
+	colorBold   = "1"
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// Checking whether output to w should be colored: only terminals are, unless disabled
+// by flag or by NO_COLOR environment variable
+func colorEnabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Wrapping text into ANSI color code, text is returned as is when color is off
+func colorize(on bool, code, text string) string {
+	if !on {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// Color of capacity utilization in percent: green for well packed knapsack, red for mostly empty one
+func utilizationColor(utilization float64) string {
+	switch {
+	case utilization >= 90:
+		return colorGreen
+	case utilization >= 50:
+		return colorYellow
+	}
+	return colorRed
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pairs of items which must not be selected together, like chemicals that can't ship together.
+// Items are named, so that pairs hold for copies and filtered instances too.
+type conflictLimit struct {
+	Pairs [][]string
+}
+
+func (c conflictLimit) Name() string {
+	return fmt.Sprintf("%d conflicting pairs apart", len(c.Pairs))
+}
+
+func (c conflictLimit) Satisfied(solution []int, items []Item) bool {
+	return len(c.violated(solution, items)) == 0
+}
+
+// Pairs of which both items are selected
+func (c conflictLimit) violated(solution []int, items []Item) [][]string {
+	selected := map[string]int{}
+	for i, included := range solution {
+		selected[items[i].Name] += included
+	}
+	var violated [][]string
+	for _, pair := range c.Pairs {
+		a, b := pair[0], pair[1]
+		if a == b && selected[a] > 1 || a != b && selected[a] > 0 && selected[b] > 0 {
+			violated = append(violated, pair)
+		}
+	}
+	return violated
+}
+
+// Constraint of conflicting pairs of the instance, none if it declares none
+func conflictConstraints(conflicts [][]string) ([]Constraint, error) {
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+	for n, pair := range conflicts {
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("conflict %d must name two items, got %d", n+1, len(pair))
+		}
+	}
+	return []Constraint{conflictLimit{Pairs: conflicts}}, nil
+}
+
+// Pair as text for messages, like "acid + bleach"
+func formatPair(pair []string) string {
+	return strings.Join(pair, " + ")
+}
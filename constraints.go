@@ -0,0 +1,59 @@
+package main
+
+import "fmt"
+
+// Constraint on selected items besides the knapsack capacity
+type Constraint interface {
+	// Description used in reports, like "risk <= 2.5"
+	Name() string
+	Satisfied(solution []int, items []Item) bool
+}
+
+// Constraint which can't be reached by adding items one by one, like fairness
+// between owners. Search passes through solutions violating it with a penalty,
+// only solutions satisfying it can become the best.
+type softConstraint interface {
+	Constraint
+	// How far solution is from satisfying the constraint, 0 when satisfied, 1 is a large violation
+	Violation(solution []int, items []Item) float64
+}
+
+// Checking constraints which are not soft, those are never violated during search
+func satisfiesHard(constraints []Constraint, solution []int, items []Item) bool {
+	for _, c := range constraints {
+		if _, soft := c.(softConstraint); !soft && !c.Satisfied(solution, items) {
+			return false
+		}
+	}
+	return true
+}
+
+// Summing violations of soft constraints
+func softViolation(constraints []Constraint, solution []int, items []Item) float64 {
+	violation := 0.0
+	for _, c := range constraints {
+		if soft, ok := c.(softConstraint); ok {
+			violation += soft.Violation(solution, items)
+		}
+	}
+	return violation
+}
+
+// Cap on total risk of selected items
+type riskBudget struct {
+	MaxRisk float64
+}
+
+func (c riskBudget) Name() string {
+	return fmt.Sprintf("risk <= %g", c.MaxRisk)
+}
+
+func (c riskBudget) Satisfied(solution []int, items []Item) bool {
+	var risk neumaierSum
+	for i, included := range solution {
+		if included == 1 {
+			risk.add(items[i].Risk)
+		}
+	}
+	return risk.value() <= c.MaxRisk
+}
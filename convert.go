@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Writing instance in given format, the counterpart of decodeInstance
+func writeInstance(w io.Writer, instance *Instance, format string, delimiter rune) error {
+	switch format {
+	case "json":
+		return writeInstanceJSON(w, instance)
+	case "ndjson":
+		return writeItemsNDJSON(w, instance.Items)
+	case "csv":
+		return writeItemsCSV(w, instance.Items, delimiter)
+	case "yaml":
+		return writeYAML(w, instance)
+	case "toml":
+		return writeTOML(w, instance)
+	case "orlib":
+		return writeORLib(w, instance)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// Instance data each format can't hold, so user can be warned about the loss
+func droppedFields(instance *Instance, format string) []string {
+	var dropped []string
+	check := func(field string, present bool, formats ...string) {
+		if !present {
+			return
+		}
+		for _, f := range formats {
+			if f == format {
+				return
+			}
+		}
+		dropped = append(dropped, field)
+	}
+
+	var names, extras, resources, assignment bool
+	for _, item := range instance.Items {
+		names = names || item.Name != ""
+		extras = extras || item.WeightSD != 0 || item.Risk != 0 || item.Category != "" || item.Owner != "" || item.Group != "" ||
+			len(item.Requires) > 0 || item.Required || item.Excluded || item.Quantity != 0
+		resources = resources || len(item.Resources) > 0
+		assignment = assignment || len(item.Weights) > 0 || len(item.Values) > 0
+	}
+	check("name", instance.Name != "", "json", "yaml", "toml")
+	check("capacity", instance.Capacity > 0, "json", "yaml", "toml", "orlib")
+	check("known_optimum", instance.KnownOptimum > 0, "json", "yaml", "toml")
+	check("curves", len(instance.Curves) > 0, "json", "yaml", "toml")
+	check("resources", len(instance.Resources) > 0, "json", "yaml", "toml")
+	check("conflicts", len(instance.Conflicts) > 0, "json", "yaml", "toml")
+	check("synergies", len(instance.Synergies) > 0, "json", "yaml", "toml")
+	check("category_limits", len(instance.CategoryLimits) > 0, "json", "yaml", "toml")
+	check("params", len(instance.Params) > 0, "toml")
+	check("item names", names, "json", "ndjson", "csv", "yaml", "toml")
+	check("item weight_sd, risk, category, owner, group, requires, markers and quantity", extras, "json", "ndjson", "csv", "yaml", "toml")
+	check("item resources", resources, "json", "ndjson", "yaml", "toml")
+	check("item weights and values by knapsack", assignment, "json", "ndjson", "yaml", "toml")
+	return dropped
+}
+
+// Writing instance as JSON: bare array of items in the original format
+// unless instance has data besides items
+func writeInstanceJSON(w io.Writer, instance *Instance) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if instance.Name == "" && instance.Capacity == 0 && instance.KnownOptimum == 0 && len(instance.Curves) == 0 &&
+		len(instance.Resources) == 0 && len(instance.Conflicts) == 0 && len(instance.CategoryLimits) == 0 &&
+		len(instance.Synergies) == 0 {
+		return encoder.Encode(instance.Items)
+	}
+	return encoder.Encode(instance)
+}
+
+// Writing items as NDJSON, one item object per line
+func writeItemsNDJSON(w io.Writer, items []Item) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	encoder.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Column of items CSV and the text of its field
+type csvColumn struct {
+	name  string
+	field func(Item) string
+}
+
+// Columns of items CSV, optional columns only if some item has them
+func itemsCSVColumns(items []Item) []csvColumn {
+	columns := []csvColumn{
+		{"name", func(item Item) string { return item.Name }},
+		{"weight", func(item Item) string { return formatFloat(item.Weight) }},
+		{"value", func(item Item) string { return strconv.Itoa(item.Value) }},
+	}
+	optional := []struct {
+		csvColumn
+		present func(Item) bool
+	}{
+		{csvColumn{"weight_sd", func(item Item) string { return formatFloat(item.WeightSD) }},
+			func(item Item) bool { return item.WeightSD != 0 }},
+		{csvColumn{"risk", func(item Item) string { return formatFloat(item.Risk) }},
+			func(item Item) bool { return item.Risk != 0 }},
+		{csvColumn{"category", func(item Item) string { return item.Category }},
+			func(item Item) bool { return item.Category != "" }},
+		{csvColumn{"owner", func(item Item) string { return item.Owner }},
+			func(item Item) bool { return item.Owner != "" }},
+		{csvColumn{"group", func(item Item) string { return item.Group }},
+			func(item Item) bool { return item.Group != "" }},
+		{csvColumn{"requires", func(item Item) string { return strings.Join(item.Requires, ";") }},
+			func(item Item) bool { return len(item.Requires) > 0 }},
+		{csvColumn{"required", func(item Item) string { return strconv.FormatBool(item.Required) }},
+			func(item Item) bool { return item.Required }},
+		{csvColumn{"excluded", func(item Item) string { return strconv.FormatBool(item.Excluded) }},
+			func(item Item) bool { return item.Excluded }},
+		{csvColumn{"quantity", func(item Item) string { return strconv.Itoa(item.Quantity) }},
+			func(item Item) bool { return item.Quantity != 0 }},
+	}
+	for _, column := range optional {
+		for _, item := range items {
+			if column.present(item) {
+				columns = append(columns, column.csvColumn)
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// Writing items as CSV with header, optional columns only if some item has them
+func writeItemsCSV(w io.Writer, items []Item, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+	columns := itemsCSVColumns(items)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	writer.Write(record)
+
+	for _, item := range items {
+		for i, column := range columns {
+			record[i] = column.field(item)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Shortest representation of number which is read back exactly
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Numbers and names separated by commas, for lists in YAML and TOML
+func joinFloats(numbers []float64) string {
+	fields := make([]string, len(numbers))
+	for i, x := range numbers {
+		fields[i] = formatFloat(x)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func joinQuoted(names []string) string {
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = strconv.Quote(name)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func joinInts(numbers []int) string {
+	fields := make([]string, len(numbers))
+	for i, x := range numbers {
+		fields[i] = strconv.Itoa(x)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// Categories of curves in sorted order, for stable output
+func sortedCategories(curves map[string]Curve) []string {
+	categories := make([]string, 0, len(curves))
+	for category := range curves {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Convert subcommand: reading instance in any supported format and writing it in another one
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	input := addInputFlags(fs)
+	output := fs.String("output", "-", "file to write converted instance to, - writes standard output")
+	outputFormat := fs.String("output-format", "", "output format: json, ndjson, csv, yaml, toml or orlib, detected by output file extension if empty")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	format := *outputFormat
+	if format == "" {
+		if *output == "-" {
+			invalid("-output-format is required when writing standard output")
+		}
+		format = detectFormat(*output)
+	}
+
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	for _, field := range droppedFields(instance, format) {
+		slog.Warn("Field can't be written in output format, dropping it", "field", field, "format", format)
+	}
+
+	if err := writeInstanceFile(*output, instance, format, []rune(*input.csvDelimiter)[0]); err != nil {
+		fatal("Error while writing the file", "err", err)
+	}
+}
+
+// Writing instance to file, "-" writes standard output
+func writeInstanceFile(filename string, instance *Instance, format string, delimiter rune) error {
+	if filename == "-" {
+		return writeInstance(os.Stdout, instance, format, delimiter)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = writeInstance(file, instance, format, delimiter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
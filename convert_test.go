@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// Every format reads back what it was written with, up to the fields it can't hold
+func TestWriteInstanceRoundTrip(t *testing.T) {
+	instance := &Instance{Name: "hike", Capacity: 4.5, Conflicts: [][]string{{"tent", "hammock"}}, Items: []Item{
+		{Name: "tent", Weight: 2.5, Value: 30, Category: "shelter", Required: true},
+		{Name: "hammock", Weight: 1, Value: 20, Category: "shelter"},
+		{Name: "map", Weight: 0.25, Value: 4, Requires: []string{"compass"}, Quantity: 2},
+		{Name: "compass", Weight: 0.125, Value: 6},
+	}}
+	bare := &Instance{Items: instance.Items}
+	tests := []struct {
+		format string
+		want   *Instance
+	}{
+		{"json", instance},
+		{"yaml", instance},
+		{"toml", instance},
+		{"ndjson", bare},
+		{"csv", bare},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeInstance(&buf, instance, tt.format, 0); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		read, err := decodeInstance(&buf, tt.format, InputOptions{Strict: true})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if !reflect.DeepEqual(read, tt.want) {
+			t.Errorf("%s: read back %+v, want %+v", tt.format, read, tt.want)
+		}
+	}
+
+	if err := writeInstance(&bytes.Buffer{}, instance, "xlsx", 0); err == nil {
+		t.Error("no error for unsupported format")
+	}
+}
+
+func TestDroppedFields(t *testing.T) {
+	instance := &Instance{Name: "hike", Capacity: 4.5, Items: []Item{{Name: "tent", Weight: 2.5, Value: 30, Category: "shelter"}}}
+	tests := map[string][]string{
+		"json":  nil,
+		"csv":   {"name", "capacity"},
+		"orlib": {"name", "item names", "item weight_sd, risk, category, owner, group, requires, markers and quantity"},
+	}
+	for format, want := range tests {
+		if dropped := droppedFields(instance, format); !reflect.DeepEqual(dropped, want) {
+			t.Errorf("%s: dropped %q, want %q", format, dropped, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Diminishing returns curve of a category: n-th selected item of the category is worth
+// its value multiplied by factor at n. Factors are linearly interpolated between points
+// and stay constant before the first and after the last point.
+type Curve []CurvePoint
+
+type CurvePoint struct {
+	Count  float64 `json:"count"`
+	Factor float64 `json:"factor"`
+}
+
+// Value factor of the n-th selected item, counting from 1
+func (c Curve) factor(n int) float64 {
+	if len(c) == 0 {
+		return 1
+	}
+	x := float64(n)
+	if x <= c[0].Count {
+		return c[0].Factor
+	}
+	for i := 1; i < len(c); i++ {
+		if x <= c[i].Count {
+			a, b := c[i-1], c[i]
+			return a.Factor + (b.Factor-a.Factor)*(x-a.Count)/(b.Count-a.Count)
+		}
+	}
+	return c[len(c)-1].Factor
+}
+
+// Selected items of a curved category for incremental evaluation: values are kept
+// sorted from the most valuable, so a flip only shifts the factors of the items after it
+type curvedCategory struct {
+	// Factor of the n-th selected item at n-1, up to the size of the category
+	factors []float64
+	values  []int
+	total   float64
+	changed bool
+}
+
+func newCurvedCategory(curve Curve, size int) *curvedCategory {
+	c := &curvedCategory{factors: make([]float64, size), values: make([]int, 0, size)}
+	for n := range c.factors {
+		c.factors[n] = curve.factor(n + 1)
+	}
+	return c
+}
+
+func (c *curvedCategory) add(value int) {
+	p := 0
+	for p < len(c.values) && c.values[p] >= value {
+		p++
+	}
+	c.values = append(c.values, 0)
+	copy(c.values[p+1:], c.values[p:])
+	c.values[p] = value
+	c.changed = true
+}
+
+func (c *curvedCategory) remove(value int) {
+	for p, v := range c.values {
+		if v == value {
+			c.values = append(c.values[:p], c.values[p+1:]...)
+			break
+		}
+	}
+	c.changed = true
+}
+
+// Total value of the selected items, summed again only if selection changed
+func (c *curvedCategory) sum() float64 {
+	if c.changed {
+		c.total = curvedTotal(c.values, c.factors)
+		c.changed = false
+	}
+	return c.total
+}
+
+func curvedTotal(values []int, factors []float64) float64 {
+	total := 0.0
+	for n, value := range values {
+		total += float64(value) * factors[n]
+	}
+	return total
+}
+
+// Calculating total value with diminishing returns and total weight of given solution.
+// Inside a category the most valuable items get the highest factors, so every
+// next item adds its marginal value on top of the ones before it. Categories are
+// summed in order of their names, the same way the evaluator sums them.
+func computeEnergyCurved(solution []int, items []Item, curves map[string]Curve, compensated bool) (int, float64) {
+	var weight neumaierSum
+	naiveWeight := 0.0
+	linear := 0
+	byCategory := map[string][]int{}
+	for i, included := range solution {
+		if included != 1 {
+			continue
+		}
+		if compensated {
+			weight.add(items[i].Weight)
+		} else {
+			naiveWeight += items[i].Weight
+		}
+		if _, ok := curves[items[i].Category]; ok {
+			byCategory[items[i].Category] = append(byCategory[items[i].Category], items[i].Value)
+		} else {
+			linear += items[i].Value
+		}
+	}
+
+	total := float64(linear)
+	for _, category := range sortedCategories(curves) {
+		values := byCategory[category]
+		sort.Sort(sort.Reverse(sort.IntSlice(values)))
+		factors := make([]float64, len(values))
+		for n := range factors {
+			factors[n] = curves[category].factor(n + 1)
+		}
+		total += curvedTotal(values, factors)
+	}
+
+	if compensated {
+		return int(math.Round(total)), weight.value()
+	}
+	return int(math.Round(total)), naiveWeight
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEvaluatorCurvedIncremental(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	categories := []string{"", "tools", "food", "books"}
+	items := make([]Item, 100)
+	for i := range items {
+		// Repeated values, so that removing picks one of equal values
+		items[i] = Item{Name: "item", Weight: r.Float64() * 10, Value: r.Intn(20), Category: categories[r.Intn(len(categories))]}
+	}
+	curves := map[string]Curve{
+		"tools": {{Count: 1, Factor: 1}, {Count: 5, Factor: 0.3}},
+		"food":  {{Count: 2, Factor: 0.9}, {Count: 4, Factor: 0.5}, {Count: 10, Factor: 0.1}},
+		"books": {{Count: 1, Factor: 0.7}},
+	}
+	for _, compensated := range []bool{false, true} {
+		eval := newEvaluator(items, Params{MaxWeight: 200, Curves: curves, CompensatedSum: compensated})
+		solution := make([]int, len(items))
+		for step := 0; step < 5000; step++ {
+			for k := r.Intn(3); k >= 0; k-- {
+				i := r.Intn(len(items))
+				solution[i] = 1 - solution[i]
+			}
+			value, weight := eval.evaluate(solution)
+			wantValue, wantWeight := computeEnergyCurved(solution, items, curves, compensated)
+			if value != wantValue || abs(weight-wantWeight) > 1e-9 {
+				t.Fatalf("compensated %v, step %d: evaluated %d and %v, full evaluation gives %d and %v",
+					compensated, step, value, weight, wantValue, wantWeight)
+			}
+		}
+	}
+}
+
+func BenchmarkEvaluatorCurved(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	items := make([]Item, 1000)
+	for i := range items {
+		items[i] = Item{Name: "item", Weight: r.Float64(), Value: r.Intn(100), Category: []string{"a", "b", "c"}[i%3]}
+	}
+	curve := Curve{{Count: 1, Factor: 1}, {Count: 50, Factor: 0.2}}
+	eval := newEvaluator(items, Params{MaxWeight: 250, Curves: map[string]Curve{"a": curve, "b": curve}})
+	solution := make([]int, len(items))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		i := r.Intn(len(items))
+		solution[i] = 1 - solution[i]
+		eval.evaluate(solution)
+	}
+}
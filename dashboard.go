@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Live terminal dashboard of a run, redrawn in place with ANSI escape codes
+type dashboard struct {
+	w        io.Writer
+	interval time.Duration
+	start    time.Time
+	drawn    time.Time
+	// Lines drawn last time, to move the cursor back over them
+	lines int
+
+	iterations, accepted int
+	// Iterations and time at the last redraw, for current speed
+	lastIterations int
+	temp           float64
+	best           int
+}
+
+func newDashboard(w io.Writer, interval time.Duration) *dashboard {
+	now := time.Now()
+	return &dashboard{w: w, interval: interval, start: now, drawn: now}
+}
+
+// Step hook counting iterations and redrawing from time to time
+func (d *dashboard) observe(s Step) {
+	d.iterations = s.Iteration
+	d.temp = s.Temp
+	d.best = s.BestValue
+	if s.Accepted {
+		d.accepted++
+	}
+	// Checking the clock is too slow for every iteration
+	if s.Iteration%256 == 0 {
+		if now := time.Now(); now.Sub(d.drawn) >= d.interval {
+			d.draw(now)
+		}
+	}
+}
+
+// Drawing the final state once the run is over
+func (d *dashboard) finish() {
+	d.draw(time.Now())
+}
+
+func (d *dashboard) draw(now time.Time) {
+	speed := float64(d.iterations-d.lastIterations) / now.Sub(d.drawn).Seconds()
+	if d.lines == 0 {
+		speed = float64(d.iterations) / now.Sub(d.start).Seconds()
+	}
+	rate := 0.0
+	if d.iterations > 0 {
+		rate = 100 * float64(d.accepted) / float64(d.iterations)
+	}
+
+	// Moving back to the first line of the previous frame and clearing each line before writing it
+	if d.lines > 0 {
+		fmt.Fprintf(d.w, "\x1b[%dA", d.lines)
+	}
+	lines := []string{
+		fmt.Sprintf("Elapsed:         %v", now.Sub(d.start).Round(time.Millisecond)),
+		fmt.Sprintf("Iterations:      %d (%.0f/s)", d.iterations, speed),
+		fmt.Sprintf("Temperature:     %.4f", d.temp),
+		fmt.Sprintf("Acceptance rate: %.1f%%", rate),
+		fmt.Sprintf("Best value:      %d", d.best),
+	}
+	for _, line := range lines {
+		fmt.Fprintf(d.w, "\r\x1b[2K%s\n", line)
+	}
+	d.lines = len(lines)
+	d.drawn = now
+	d.lastIterations = d.iterations
+}
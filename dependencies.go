@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Items can only be selected together with the items they require, like accessories
+// with their base unit. Items are named, so that copies of a required item all count.
+type dependencyLimit struct{}
+
+func (c dependencyLimit) Name() string {
+	return "required items selected"
+}
+
+func (c dependencyLimit) Satisfied(solution []int, items []Item) bool {
+	return len(c.missing(solution, items)) == 0
+}
+
+// Selected items with a required item not selected, as "item requires name"
+func (c dependencyLimit) missing(solution []int, items []Item) []string {
+	selected := map[string]bool{}
+	for i, included := range solution {
+		if included == 1 {
+			selected[items[i].Name] = true
+		}
+	}
+	var missing []string
+	for i, item := range items {
+		if solution[i] != 1 {
+			continue
+		}
+		for _, name := range item.Requires {
+			if !selected[name] {
+				missing = append(missing, fmt.Sprintf("%s requires %s", item.Name, name))
+			}
+		}
+	}
+	return missing
+}
+
+// Dependency constraint, none if no item requires another
+func dependencyConstraints(items []Item) []Constraint {
+	for _, item := range items {
+		if len(item.Requires) > 0 {
+			return []Constraint{dependencyLimit{}}
+		}
+	}
+	return nil
+}
+
+// Parsing required item names of CSV column, separated by semicolons
+func splitRequires(field string) []string {
+	var names []string
+	for _, name := range strings.Split(field, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
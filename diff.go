@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Difference between two solutions of the same instance
+type solutionDiff struct {
+	Added   []string
+	Removed []string
+	// Number of items included in one solution only
+	Distance    int
+	ValueDelta  int
+	WeightDelta float64
+}
+
+// Names of selected items of solution, taken from items when the solution has flags only
+func selectedNames(saved *savedSolution, items []Item) ([]string, error) {
+	if saved.Items != nil {
+		return saved.Items, nil
+	}
+	if items == nil {
+		return nil, fmt.Errorf("solution has no item names, -input is needed")
+	}
+	if len(saved.Solution) != len(items) {
+		return nil, fmt.Errorf("solution has %d entries for %d items", len(saved.Solution), len(items))
+	}
+	var names []string
+	for i, included := range saved.Solution {
+		if included == 1 {
+			names = append(names, items[i].Name)
+		}
+	}
+	return names, nil
+}
+
+// Comparing solutions by selected item names, duplicate names are counted
+func diffSolutions(a, b *savedSolution, items []Item) (solutionDiff, error) {
+	namesA, err := selectedNames(a, items)
+	if err != nil {
+		return solutionDiff{}, err
+	}
+	namesB, err := selectedNames(b, items)
+	if err != nil {
+		return solutionDiff{}, err
+	}
+	counts := map[string]int{}
+	for _, name := range namesA {
+		counts[name]--
+	}
+	for _, name := range namesB {
+		counts[name]++
+	}
+
+	d := solutionDiff{ValueDelta: b.Value - a.Value, WeightDelta: b.Weight - a.Weight}
+	for name, count := range counts {
+		for ; count > 0; count-- {
+			d.Added = append(d.Added, name)
+		}
+		for ; count < 0; count++ {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	d.Distance = len(d.Added) + len(d.Removed)
+	return d, nil
+}
+
+// Print difference, with weights and values of items when they are known
+func showDiff(w io.Writer, d solutionDiff, items []Item) {
+	byName := map[string]Item{}
+	for _, item := range items {
+		if _, ok := byName[item.Name]; !ok {
+			byName[item.Name] = item
+		}
+	}
+	list := func(sign string, names []string) {
+		for _, name := range names {
+			if item, ok := byName[name]; ok {
+				fmt.Fprintf(w, " %s %s (Weight: %s, Value: %d)\n", sign, name, formatFloat(item.Weight), item.Value)
+			} else {
+				fmt.Fprintf(w, " %s %s\n", sign, name)
+			}
+		}
+	}
+	fmt.Fprintf(w, "Added %d items:\n", len(d.Added))
+	list("+", d.Added)
+	fmt.Fprintf(w, "Removed %d items:\n", len(d.Removed))
+	list("-", d.Removed)
+	fmt.Fprintf(w, "Value delta: %+d\n", d.ValueDelta)
+	fmt.Fprintf(w, "Weight delta: %+.4f\n", d.WeightDelta)
+	fmt.Fprintf(w, "Hamming distance: %d\n", d.Distance)
+}
+
+// Diff subcommand: comparing two solution files, or the last two solves of the current session
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	input := addInputFlags(fs)
+	logging := addLogFlags(fs)
+	files := parseInterspersed(fs, args)
+	logging.apply()
+
+	if len(files) == 0 {
+		s, err := currentSession()
+		if err != nil {
+			fatal("Error while opening the current session", "err", err)
+		}
+		if s == nil {
+			invalid("Usage: diff [flags] old.json new.json, or diff within a session")
+		}
+		history, err := s.history()
+		if err != nil {
+			fatal("Error while reading history", "err", err)
+		}
+		if len(history) < 2 {
+			invalid("Session has less than two solves to compare", "session", s.Name)
+		}
+		for _, entry := range history[len(history)-2:] {
+			files = append(files, filepath.Join(s.dir, entry.Run))
+		}
+		if !flagSet(fs, "input") {
+			fs.Set("input", s.problemFile())
+		}
+	}
+	if len(files) != 2 {
+		invalid("Usage: diff [flags] old.json new.json, or diff within a session")
+	}
+
+	// Instance is optional, solutions usually name their items
+	var items []Item
+	if flagSet(fs, "input") {
+		instance, err := input.read()
+		if err != nil {
+			invalid("Error while reading the file", "err", err)
+		}
+		// Solutions have flags of every copy of items with quantities
+		_, items = expandQuantities(instance.Items)
+	}
+	var solutions []*savedSolution
+	for _, filename := range files {
+		saved, err := readSolution(filename)
+		if err != nil {
+			invalid("Error while reading the solution", "err", err)
+		}
+		solutions = append(solutions, saved)
+	}
+	d, err := diffSolutions(solutions[0], solutions[1], items)
+	if err != nil {
+		invalid("Error while comparing solutions", "err", err)
+	}
+	fmt.Printf("Comparing %s with %s\n", files[0], files[1])
+	showDiff(os.Stdout, d, items)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffSolutions(t *testing.T) {
+	items := []Item{{Name: "tent", Weight: 2, Value: 30}, {Name: "map", Weight: 0.25, Value: 4},
+		{Name: "map", Weight: 0.25, Value: 4}, {Name: "stove", Weight: 1.5, Value: 12}}
+	a := &savedSolution{Value: 38, Weight: 2.5, Solution: []int{1, 1, 1, 0}}
+	b := &savedSolution{Value: 46, Weight: 3.75, Items: []string{"tent", "map", "stove"}}
+	d, err := diffSolutions(a, b, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One of the duplicate maps is removed
+	want := solutionDiff{Added: []string{"stove"}, Removed: []string{"map"}, Distance: 2, ValueDelta: 8, WeightDelta: 1.25}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("diff %+v, want %+v", d, want)
+	}
+
+	var buf bytes.Buffer
+	showDiff(&buf, d, items)
+	for _, line := range []string{" + stove (Weight: 1.5, Value: 12)", " - map (Weight: 0.25, Value: 4)",
+		"Value delta: +8", "Hamming distance: 2"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("output has no %q:\n%s", line, buf.String())
+		}
+	}
+
+	// Flags without items, or with a different number of items, can't be named
+	if _, err := diffSolutions(a, b, nil); err == nil {
+		t.Error("no error without items")
+	}
+	if _, err := diffSolutions(a, b, items[:3]); err == nil {
+		t.Error("no error for a different number of items")
+	}
+}
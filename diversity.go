@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+)
+
+// Number of positions solutions differ at
+func hammingDistance(a, b []int) int {
+	distance := 0
+	for i := range a {
+		if a[i] != b[i] {
+			distance++
+		}
+	}
+	return distance
+}
+
+// Solution of others nearest to solution and the distance to it, nil and length of solution if others are empty
+func nearestSolution(solution []int, others [][]int) ([]int, int) {
+	var nearest []int
+	distance := len(solution)
+	for _, other := range others {
+		if d := hammingDistance(solution, other); nearest == nil || d < distance {
+			nearest, distance = other, d
+		}
+	}
+	return nearest, distance
+}
+
+// Dropping random included items which are not locked until solution fits into the knapsack
+func dropRandomly(solution []int, items []Item, maxWeight float64, locked []int, rnd *rand.Rand) {
+	_, totalWeight := computeEnergy(solution, items)
+	isLocked := make(map[int]bool, len(locked))
+	for _, i := range locked {
+		isLocked[i] = true
+	}
+	var droppable []int
+	for i, included := range solution {
+		if included == 1 && !isLocked[i] {
+			droppable = append(droppable, i)
+		}
+	}
+	rnd.Shuffle(len(droppable), func(a, b int) {
+		droppable[a], droppable[b] = droppable[b], droppable[a]
+	})
+	for _, i := range droppable {
+		if totalWeight <= maxWeight {
+			break
+		}
+		solution[i] = 0
+		totalWeight -= items[i].Weight
+	}
+}
+
+// Initial solution at least minDistance flips away from every avoided solution.
+// The usual initial solution is taken if it is far enough, then random feasible
+// solutions are tried, and the opposite of the nearest avoided solution is the last resort.
+func diverseStart(items []Item, params Params, avoid [][]int, minDistance int, rnd *rand.Rand) []int {
+	solution := initialSolution(items, params, rnd)
+	if _, d := nearestSolution(solution, avoid); d >= minDistance {
+		return solution
+	}
+	for try := 0; try < 100; try++ {
+		candidate := randomSolution(items, rnd)
+		lockItems(candidate, params.Locked)
+		dropRandomly(candidate, items, params.MaxWeight, params.Locked, rnd)
+		if _, d := nearestSolution(candidate, avoid); d >= minDistance {
+			return candidate
+		}
+	}
+
+	// Opposition-based start, may still be too near when capacity forces dropping many items
+	nearest, _ := nearestSolution(solution, avoid)
+	opposite := make([]int, len(nearest))
+	for i, included := range nearest {
+		opposite[i] = 1 - included
+	}
+	lockItems(opposite, params.Locked)
+	dropRandomly(opposite, items, params.MaxWeight, params.Locked, rnd)
+	return opposite
+}
+
+// Running restarts in rounds of concurrent runs, starting every run at least minDistance
+// flips away from the best solutions of earlier rounds and the starts of the same round,
+// so that restarts cover more of the search space than independent ones
+func (o *Orchestrator) DiverseRestarts(ctx context.Context, items []Item, params Params, n, minDistance int,
+	solver Solver) Result {
+	ctx, cancel := o.context(ctx)
+	defer cancel()
+
+	configs := restartConfigs(params, n)
+	round := o.workers()
+	var results []Result
+	var bests [][]int
+	for first := 0; first < n && ctx.Err() == nil; first += round {
+		last := first + round
+		if last > n {
+			last = n
+		}
+		avoid := append([][]int(nil), bests...)
+		for i := first; i < last; i++ {
+			configs[i].Initial = diverseStart(items, configs[i], avoid, minDistance, newRand(configs[i]))
+			avoid = append(avoid, configs[i].Initial)
+		}
+		for _, result := range o.runAll(ctx, items, configs[first:last], solver) {
+			results = append(results, result)
+			bests = append(bests, result.Solution)
+		}
+	}
+	return bestResult(results, o.Canonical)
+}
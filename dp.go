@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Largest table dynamic programming solver fills, items times capacity units
+const maxDPCells = 50_000_000
+
+// Factor making all weights and capacity whole numbers, at most 4 decimal places are tried
+func weightScale(items []Item) (float64, bool) {
+	for scale := 1.0; scale <= 1e4; scale *= 10 {
+		whole := true
+		for _, item := range items {
+			w := item.Weight * scale
+			if math.Abs(w-math.Round(w)) > 1e-6 {
+				whole = false
+				break
+			}
+		}
+		if whole {
+			return scale, true
+		}
+	}
+	return 0, false
+}
+
+// Checking whether dynamic programming solver can solve items exactly with params
+func dpApplicable(items []Item, params Params) error {
+	if len(params.Constraints) > 0 || len(params.Curves) > 0 || len(params.Synergies) > 0 {
+		return fmt.Errorf("constraints, curves and synergies are not supported")
+	}
+	scale, ok := weightScale(items)
+	if !ok {
+		return fmt.Errorf("weights have more than 4 decimal places")
+	}
+	if cells := float64(len(items)+1) * (params.MaxWeight*scale + 1); cells > maxDPCells {
+		return fmt.Errorf("table of %.0f cells is too large", cells)
+	}
+	return nil
+}
+
+// Exact solver filling the classic table of the best value by items and capacity,
+// for weights with few decimal places and no constraints besides capacity and locked items.
+// Stops early with an empty solution when context is done.
+func dpSolver(ctx context.Context, items []Item, params Params) Result {
+	start := time.Now()
+	if err := dpApplicable(items, params); err != nil {
+		panic("dynamic programming solver: " + err.Error())
+	}
+	scale, _ := weightScale(items)
+	weights := make([]int, len(items))
+	for i, item := range items {
+		weights[i] = int(math.Round(item.Weight * scale))
+	}
+
+	// Locked items are taken first and the rest is solved for remaining capacity
+	solution := make([]int, len(items))
+	lockItems(solution, params.Locked)
+	capacity := int(math.Floor(params.MaxWeight*scale + 1e-6))
+	for i, included := range solution {
+		if included == 1 {
+			capacity -= weights[i]
+		}
+	}
+	result := Result{Solution: solution, Seed: params.Seed, Algorithm: "dynamic-programming"}
+	if capacity < 0 {
+		result.Value, result.Weight = computeEnergy(solution, items)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	// best[c] is the best value within capacity c of the items considered so far,
+	// take[i][c] tells whether item i is in that best subset. Items are considered from
+	// the last one and taken only when they improve the value, so that walking back from
+	// the first one leaves out earlier items on ties, giving the canonical optimum.
+	best := make([]int, capacity+1)
+	take := make([][]bool, len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		take[i] = make([]bool, capacity+1)
+		if solution[i] == 1 || item.Value <= 0 {
+			continue
+		}
+		for c := capacity; c >= weights[i]; c-- {
+			if v := best[c-weights[i]] + item.Value; v > best[c] {
+				best[c] = v
+				take[i][c] = true
+			}
+		}
+		if ctx.Err() != nil {
+			result.Value, result.Weight = computeEnergy(solution, items)
+			result.Duration = time.Since(start)
+			return result
+		}
+	}
+
+	// Walking back through the table
+	c := capacity
+	for i := range items {
+		if take[i][c] {
+			solution[i] = 1
+			c -= weights[i]
+		}
+	}
+	result.Value, result.Weight = computeEnergy(solution, items)
+	result.Iterations = len(items)
+	result.Duration = time.Since(start)
+	return result
+}
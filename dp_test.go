@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// Dynamic programming walks back to the canonical optimum, like canonical exhaustive runs
+func TestDPCanonical(t *testing.T) {
+	items := []Item{
+		{Name: "a", Weight: 1, Value: 5},
+		{Name: "b", Weight: 1, Value: 5},
+		{Name: "c", Weight: 2, Value: 10},
+		{Name: "d", Weight: 1, Value: 3},
+	}
+	params := Params{MaxWeight: 2, Canonical: true}
+	want := exhaustiveSolver(context.Background(), items, params).Solution
+	if got := dpSolver(context.Background(), items, params).Solution; !reflect.DeepEqual(got, want) {
+		t.Errorf("solution %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Score of a selection given by client
+type Evaluation struct {
+	Value    int     `json:"value"`
+	Weight   float64 `json:"weight"`
+	Feasible bool    `json:"feasible"`
+	// Weight over the capacity, zero if selection fits
+	Overweight float64 `json:"overweight,omitempty"`
+	// Names of violated constraints, capacity included
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Scorer of arbitrary selections against a loaded problem, for external optimizers
+// using the tool as a verifier. Not safe for concurrent use because of the cache.
+type Scorer struct {
+	items  []Item
+	params Params
+	eval   *evaluator
+	names  map[string]int
+}
+
+func newScorer(items []Item, params Params) *Scorer {
+	names := make(map[string]int, len(items))
+	for i, item := range items {
+		if _, ok := names[item.Name]; !ok {
+			names[item.Name] = i
+		}
+	}
+	return &Scorer{items: items, params: params, eval: newEvaluator(items, params), names: names}
+}
+
+// Scoring selection of 0/1 flags, one per item
+func (s *Scorer) Evaluate(selection []int) (Evaluation, error) {
+	if len(selection) != len(s.items) {
+		return Evaluation{}, fmt.Errorf("selection has %d flags, instance has %d items", len(selection), len(s.items))
+	}
+	for i, included := range selection {
+		if included != 0 && included != 1 {
+			return Evaluation{}, fmt.Errorf("flag %d must be 0 or 1, got %d", i+1, included)
+		}
+	}
+
+	value, weight := s.eval.evaluate(selection)
+	e := Evaluation{Value: value, Weight: weight}
+	if weight > s.params.MaxWeight {
+		e.Overweight = weight - s.params.MaxWeight
+		e.Violations = append(e.Violations, fmt.Sprintf("weight <= %g", s.params.MaxWeight))
+	}
+	for _, c := range s.params.Constraints {
+		if !c.Satisfied(selection, s.items) {
+			e.Violations = append(e.Violations, c.Name())
+		}
+	}
+	e.Feasible = len(e.Violations) == 0
+	return e, nil
+}
+
+// Parsing selection given as string of 0/1 flags like "0110", JSON array of flags
+// or JSON array of item names
+func (s *Scorer) parseSelection(text string) ([]int, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") {
+		selection := make([]int, len(text))
+		for i, c := range text {
+			if c != '0' && c != '1' {
+				return nil, fmt.Errorf("unexpected character %q at position %d, expected 0 or 1", c, i+1)
+			}
+			selection[i] = int(c - '0')
+		}
+		return selection, nil
+	}
+
+	var flags []int
+	if err := json.Unmarshal([]byte(text), &flags); err == nil {
+		return flags, nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(text), &names); err != nil {
+		return nil, fmt.Errorf("expected JSON array of 0/1 flags or item names")
+	}
+	selection := make([]int, len(s.items))
+	for _, name := range names {
+		i, ok := s.names[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown item %q", name)
+		}
+		selection[i] = 1
+	}
+	return selection, nil
+}
+
+// Scoring selections from r, one per line, writing one JSON result per line to w.
+// Bad lines get an error result instead of stopping the stream.
+func (s *Scorer) evaluateStream(r io.Reader, w io.Writer) error {
+	type lineResult struct {
+		Line int `json:"line"`
+		*Evaluation
+		Error string `json:"error,omitempty"`
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		result := lineResult{Line: line}
+		selection, err := s.parseSelection(text)
+		if err == nil {
+			var e Evaluation
+			e, err = s.Evaluate(selection)
+			result.Evaluation = &e
+		}
+		if err != nil {
+			result.Evaluation = nil
+			result.Error = err.Error()
+		}
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// Evaluate subcommand: scoring selections against the instance without solving it
+func runEvaluate(args []string) {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	input := addInputFlags(fs)
+	maxWeight := fs.Float64("capacity", 5.0, "max weight of the knapsack")
+	kahan := fs.Bool("kahan", false, "sum weights with compensated summation")
+	cacheSize := fs.Int("cache-size", 0, "number of evaluated selections to cache, 0 disables the cache")
+	constraints := addConstraintFlags(fs)
+	selectionsFile := fs.String("selections", "-", "file with selections, one per line: 0/1 string, JSON array of flags or of item names, - reads standard input")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	// Solver params of the input don't matter here, only its capacity
+	if instance.Capacity > 0 {
+		if err := applyConfig(fs, map[string]interface{}{"capacity": instance.Capacity}); err != nil {
+			invalid("Error in input capacity", "err", err)
+		}
+	}
+
+	limits, err := constraints.list(instance, *maxWeight)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	params := Params{
+		MaxWeight:      *maxWeight,
+		CacheSize:      *cacheSize,
+		CompensatedSum: *kahan,
+		Curves:         instance.Curves,
+		Synergies:      instance.Synergies,
+		Constraints:    limits,
+	}
+
+	var r io.Reader = os.Stdin
+	if *selectionsFile != "-" {
+		file, err := os.Open(*selectionsFile)
+		if err != nil {
+			invalid("Error while reading selections", "err", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	if err := newScorer(instance.Items, params).evaluateStream(r, os.Stdout); err != nil {
+		fatal("Error while evaluating selections", "err", err)
+	}
+}
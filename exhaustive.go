@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Largest instance exhaustive solver takes, 2^30 subsets already take minutes
+const maxExhaustiveItems = 30
+
+// Enumerating all subsets of items in Gray code order, so that consecutive subsets differ
+// by one item and totals are updated incrementally. Best solution has the least soft
+// constraint violation and the highest value, the pool ranks the best subsets satisfying
+// all constraints. Stops early with the best subset so far when context is done.
+func exhaustiveSolver(ctx context.Context, items []Item, params Params) Result {
+	start := time.Now()
+	if len(items) > maxExhaustiveItems {
+		panic(fmt.Sprintf("exhaustive solver takes at most %d items, got %d", maxExhaustiveItems, len(items)))
+	}
+	eval := newEvaluator(items, params)
+	var pool *solutionPool
+	if params.PoolSize > 0 {
+		pool = newSolutionPool(params.PoolSize, params.PoolDistance)
+	}
+	locked := make([]int, len(items))
+	lockItems(locked, params.Locked)
+
+	solution := make([]int, len(items))
+	value, weight := 0, 0.0
+	best := Result{Solution: make([]int, len(items)), Violation: -1, Algorithm: "exhaustive"}
+	bestIteration := 0
+	count := uint64(1) << len(items)
+	enumerated := 0
+	for n := uint64(0); n < count; n++ {
+		// Flipping the item of the lowest set bit of n, subset 0 is the empty one
+		if n > 0 {
+			i := bits.TrailingZeros64(n)
+			solution[i] = 1 - solution[i]
+			if solution[i] == 1 {
+				value += items[i].Value
+				weight += items[i].Weight
+			} else {
+				value -= items[i].Value
+				weight -= items[i].Weight
+			}
+		}
+		// Incremental sums drift, so totals near capacity are recomputed exactly
+		if math.Abs(weight-params.MaxWeight) < 1e-6 {
+			value, weight = computeEnergy(solution, items)
+		}
+		enumerated++
+		if n%(1<<16) == 0 && ctx.Err() != nil {
+			break
+		}
+
+		if weight > params.MaxWeight || !includesLocked(solution, locked) ||
+			!satisfiesHard(params.Constraints, solution, items) {
+			continue
+		}
+		// Totals are exact only for plain sums, curves, synergies and compensated sums need full evaluation
+		v, w := value, weight
+		if len(params.Curves) > 0 || len(params.Synergies) > 0 || params.CompensatedSum {
+			v, w = eval.evaluate(solution)
+			if w > params.MaxWeight {
+				continue
+			}
+		}
+		violation := softViolation(params.Constraints, solution, items)
+		if violation == 0 && pool != nil {
+			pool.offer(solution, v, w)
+		}
+		// Equal-value subsets are taken in Gray code order, canonical runs take the lexicographically smallest
+		if best.Violation < 0 || violation < best.Violation || violation == best.Violation && v > best.Value ||
+			params.Canonical && violation == best.Violation && v == best.Value && lexLess(solution, best.Solution) {
+			copy(best.Solution, solution)
+			best.Value, best.Weight, best.Violation = v, w, violation
+			bestIteration = enumerated
+		}
+	}
+
+	// No feasible subset at all, when locked items don't fit or hard constraints can't be met
+	if best.Violation < 0 {
+		best.Violation = 0
+		best.Solution = make([]int, len(items))
+	}
+	best.Pool = pool
+	best.Iterations = enumerated
+	best.BestIteration = bestIteration
+	best.Seed = params.Seed
+	best.Duration = time.Since(start)
+	return best
+}
+
+// Checking that solution includes every item marked in locked
+func includesLocked(solution, locked []int) bool {
+	for i, l := range locked {
+		if l == 1 && solution[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Print ranking of the best solutions of the pool, weights with as many decimals as in the report
+func showRanking(w io.Writer, pool *solutionPool, items []Item) {
+	decimals := weightDecimals(items)
+	fmt.Fprintf(w, "\nTop %d solutions:\n", len(pool.entries))
+	for rank, entry := range pool.entries {
+		var names []string
+		for i, included := range entry.Solution {
+			if included == 1 {
+				names = append(names, items[i].Name)
+			}
+		}
+		fmt.Fprintf(w, "%d. value %d, weight %s: %s\n", rank+1, entry.Value,
+			strconv.FormatFloat(entry.Weight, 'f', decimals, 64), strings.Join(names, ", "))
+	}
+}
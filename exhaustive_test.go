@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// Canonical runs break ties of equal value the way annealing does, not in Gray code order
+func TestExhaustiveCanonical(t *testing.T) {
+	items := []Item{{Name: "a", Weight: 1, Value: 5}, {Name: "b", Weight: 1, Value: 5}}
+	result := exhaustiveSolver(context.Background(), items, Params{MaxWeight: 1, Canonical: true})
+	if want := []int{0, 1}; !reflect.DeepEqual(result.Solution, want) {
+		t.Errorf("solution %v, want %v", result.Solution, want)
+	}
+}
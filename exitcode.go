@@ -0,0 +1,73 @@
+package main
+
+// Process exit codes, so that scripts can tell bad data from solver problems
+const (
+	exitOK = 0
+	// Reading, writing and other unexpected errors
+	exitFailure = 1
+	// Invalid flags or input data, flag package exits with it on usage errors too
+	exitInvalid = 2
+	// No feasible solution exists: no item fits, locked and required items exceed capacity
+	// or break hard constraints
+	exitInfeasible = 3
+	// Time limit reached before a solution satisfying all constraints was found
+	exitTimeout = 4
+	// Solution satisfying all soft constraints not found within the iterations
+	exitUnsatisfied = 5
+	// Run invariant violated, found by -check-trace
+	exitInvariant = 6
+	// Solution checked by verify subcommand does not match the instance
+	exitMismatch = 7
+)
+
+// Exiting with infeasible code when no solution can exist: no item fits or locked and required items don't fit together
+func checkFeasible(items []Item, params Params) {
+	locked := 0.0
+	for _, i := range params.Locked {
+		locked += items[i].Weight
+	}
+	if locked > params.MaxWeight {
+		exit(exitInfeasible, "Locked and required items don't fit into the knapsack", "weight", locked, "capacity", params.MaxWeight)
+	}
+	if c := lockedViolation(items, params); c != nil {
+		exit(exitInfeasible, "Locked and required items break a constraint", "constraint", c.Name())
+	}
+	for _, c := range params.Constraints {
+		switch c := c.(type) {
+		case itemCountLimit:
+			if len(params.Locked) > c.Max {
+				exit(exitInfeasible, "More locked and required items than items allowed", "locked", len(params.Locked), "max_items", c.Max)
+			}
+		case itemCountMinimum:
+			if len(items) < c.Min {
+				exit(exitInfeasible, "Fewer items than items needed", "items", len(items), "min_items", c.Min)
+			}
+		}
+	}
+	for _, item := range items {
+		if item.Weight <= params.MaxWeight {
+			return
+		}
+	}
+	exit(exitInfeasible, "No item fits into the knapsack", "capacity", params.MaxWeight)
+}
+
+// Hard constraint locked and required items break on their own, nil if there is none.
+// Adding items never mends hard constraints but dependencies, which are met by adding
+// the required items.
+func lockedViolation(items []Item, params Params) Constraint {
+	selection := make([]int, len(items))
+	lockItems(selection, params.Locked)
+	for _, c := range params.Constraints {
+		if _, soft := c.(softConstraint); soft {
+			continue
+		}
+		if _, dependency := c.(dependencyLimit); dependency {
+			continue
+		}
+		if !c.Satisfied(selection, items) {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+// Required items of a conflicting pair make the instance infeasible, items they require don't
+func TestLockedViolation(t *testing.T) {
+	items := []Item{
+		{Name: "stove", Weight: 1, Value: 5, Requires: []string{"fuel"}},
+		{Name: "fuel", Weight: 1, Value: 1},
+		{Name: "candle", Weight: 1, Value: 2},
+	}
+	conflicts, err := conflictConstraints([][]string{{"stove", "candle"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := Params{MaxWeight: 5, Constraints: append(conflicts, dependencyLimit{}), Locked: []int{0}}
+	if c := lockedViolation(items, params); c != nil {
+		t.Errorf("stove alone breaks %s", c.Name())
+	}
+	params.Locked = []int{0, 2}
+	if c := lockedViolation(items, params); c == nil || c.Name() != conflicts[0].Name() {
+		t.Errorf("stove and candle break %v, want the conflict", c)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build fastexp
+
+package main
+
+import "math"
+
+// Approximate exponential for acceptance probability, built with fastexp tag.
+// Argument is split into k*ln2 + r with |r| <= ln2/2, exp(r) is a degree 5 polynomial
+// and 2^k is put into the exponent bits directly. Relative error is below 4e-6,
+// well within 1e-4 needed for comparing with a random number.
+func acceptExp(x float64) float64 {
+	switch {
+	case x < -708:
+		return 0
+	case x > 709:
+		return math.Inf(1)
+	}
+
+	k := math.Floor(x*math.Log2E + 0.5)
+	r := x - k*math.Ln2
+	p := 1 + r*(1+r*(1.0/2+r*(1.0/6+r*(1.0/24+r*(1.0/120)))))
+	return p * math.Float64frombits(uint64(int64(k)+1023)<<52)
+}
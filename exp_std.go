@@ -0,0 +1,10 @@
+//go:build !fastexp
+
+package main
+
+import "math"
+
+// Exponential of acceptance probability, exact unless built with fastexp tag
+func acceptExp(x float64) float64 {
+	return math.Exp(x)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Annealing accepts worse candidates with exp of value loss over temperature, which is never
+// positive, and probabilities below exp(-50) are never hit by a random number
+func TestAcceptExpError(t *testing.T) {
+	for x := -50.0; x <= 0; x += 1e-3 {
+		want := math.Exp(x)
+		if err := math.Abs(acceptExp(x)-want) / want; err > 1e-5 {
+			t.Fatalf("relative error of acceptExp(%v) is %v", x, err)
+		}
+	}
+	if got := acceptExp(-1000); got != 0 {
+		t.Errorf("acceptExp(-1000) is %v, want 0", got)
+	}
+}
+
+func BenchmarkAcceptExp(b *testing.B) {
+	sum := 0.0
+	for i := 0; i < b.N; i++ {
+		sum += acceptExp(-float64(i%5000) * 1e-2)
+	}
+	if sum < 0 {
+		b.Fatal(sum)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Why an excluded item is not in the solution
+type exclusion struct {
+	Item int
+	// Heavier than the whole capacity left besides locked items
+	TooHeavy bool
+	// Included items to drop for it to fit, the least dense first, none if it fits already
+	Displaced []int
+	// Value change of taking the item in place of the displaced ones
+	Change int
+	// Constraints taking the item would break
+	Broken []string
+}
+
+// Explaining excluded items, the most valuable first, at most limit of them
+func explainExclusions(solution []int, items []Item, params Params, limit int) []exclusion {
+	eval := newEvaluator(items, params)
+	value, weight := eval.evaluate(solution)
+	locked := make([]bool, len(items))
+	lockedWeight := 0.0
+	for _, i := range params.Locked {
+		if !locked[i] {
+			locked[i] = true
+			lockedWeight += items[i].Weight
+		}
+	}
+	// Items that can be dropped to make room, the least dense first
+	var droppable []int
+	for i, included := range solution {
+		if included == 1 && !locked[i] {
+			droppable = append(droppable, i)
+		}
+	}
+	sort.SliceStable(droppable, func(a, b int) bool {
+		return density(items[droppable[a]]) < density(items[droppable[b]])
+	})
+
+	var excluded []int
+	for i, included := range solution {
+		if included == 0 {
+			excluded = append(excluded, i)
+		}
+	}
+	sort.SliceStable(excluded, func(a, b int) bool {
+		return items[excluded[a]].Value > items[excluded[b]].Value
+	})
+	if limit > 0 && len(excluded) > limit {
+		excluded = excluded[:limit]
+	}
+
+	var exclusions []exclusion
+	for _, i := range excluded {
+		e := exclusion{Item: i}
+		if lockedWeight+items[i].Weight > params.MaxWeight {
+			e.TooHeavy = true
+			exclusions = append(exclusions, e)
+			continue
+		}
+		candidate := append([]int(nil), solution...)
+		candidate[i] = 1
+		free := params.MaxWeight - weight - items[i].Weight
+		for _, j := range droppable {
+			if free >= 0 {
+				break
+			}
+			candidate[j] = 0
+			free += items[j].Weight
+			e.Displaced = append(e.Displaced, j)
+		}
+		v, _ := eval.evaluate(candidate)
+		e.Change = v - value
+		for _, c := range params.Constraints {
+			if !c.Satisfied(candidate, items) {
+				e.Broken = append(e.Broken, c.Name())
+			}
+		}
+		exclusions = append(exclusions, e)
+	}
+	return exclusions
+}
+
+// Print why excluded items are out, in words for people reading the plan
+func showExclusions(w io.Writer, exclusions []exclusion, items []Item) {
+	if len(exclusions) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nWhy items are left out:\n")
+	for _, e := range exclusions {
+		item := items[e.Item]
+		fmt.Fprintf(w, " %s (Weight: %s, Value: %d): ", item.Name, formatFloat(item.Weight), item.Value)
+		switch {
+		case e.TooHeavy:
+			fmt.Fprintf(w, "too heavy for the knapsack")
+		case len(e.Displaced) == 0:
+			fmt.Fprintf(w, "fits into the remaining capacity, value %+d", e.Change)
+		default:
+			// Long lists are cut, the least dense items matter the most
+			var names []string
+			for n, j := range e.Displaced {
+				if n == 5 {
+					names = append(names, fmt.Sprintf("%d more", len(e.Displaced)-n))
+					break
+				}
+				names = append(names, items[j].Name)
+			}
+			fmt.Fprintf(w, "would displace %s, value %+d", strings.Join(names, ", "), e.Change)
+		}
+		if len(e.Broken) > 0 {
+			fmt.Fprintf(w, ", breaking %s", strings.Join(e.Broken, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
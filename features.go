@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Experimental solvers and neighborhoods, disabled by default.
+// Features are enabled per deployment with KNAPSACK_FEATURES environment variable
+// or "features" config param, and per run with -features flag.
+var experimentalFeatures = map[string]string{
+	"fallback": "falling back from exact solvers to annealing",
+}
+
+// Set of enabled experimental features
+type Features map[string]bool
+
+// Resolving enabled features: environment first, then the list given to the run.
+// Lists are comma separated, "-name" disables a feature enabled earlier.
+func resolveFeatures(list string) (Features, error) {
+	features := Features{}
+	for _, source := range []string{os.Getenv("KNAPSACK_FEATURES"), list} {
+		for _, name := range strings.Split(source, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			enabled := !strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			if _, ok := experimentalFeatures[name]; !ok {
+				return nil, fmt.Errorf("unknown feature %q", name)
+			}
+			features[name] = enabled
+		}
+	}
+	return features, nil
+}
+
+// Checking that experimental feature is enabled
+func (f Features) require(name string) error {
+	if description, ok := experimentalFeatures[name]; ok && !f[name] {
+		return fmt.Errorf("%s is experimental, enable it with -features %s or KNAPSACK_FEATURES=%s", description, name, name)
+	}
+	return nil
+}
+
+// Sorted names of all experimental features, for flag usage
+func featureNames() string {
+	var names []string
+	for name := range experimentalFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Sorted names of enabled features
+func (f Features) List() []string {
+	var names []string
+	for name, enabled := range f {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// What-if filters of items applied before solving
+type itemFilter struct {
+	// Items with lower value per weight are left out, 0 keeps all
+	MinDensity float64
+	// Items heavier than this are left out, 0 keeps all
+	MaxWeight float64
+	// Names of items to leave out
+	Exclude []string
+	// Items marked excluded are left out
+	Marked bool
+}
+
+// Item left out by filter with the reason
+type filteredItem struct {
+	Item   Item
+	Reason string
+}
+
+// Checking if filter leaves any item out
+func (f itemFilter) active() bool {
+	return f.MinDensity > 0 || f.MaxWeight > 0 || len(f.Exclude) > 0 || f.Marked
+}
+
+// Leaving out filtered items, returning kept ones with mapping to all items,
+// filtered ones and excluded names not matching any item
+func filterItems(items []Item, f itemFilter) (*reduction, []filteredItem, []string) {
+	excluded := map[string]bool{}
+	for _, name := range f.Exclude {
+		excluded[name] = true
+	}
+	matched := map[string]bool{}
+
+	r := &reduction{total: len(items)}
+	var filtered []filteredItem
+	for i, item := range items {
+		reason := ""
+		switch {
+		case excluded[item.Name]:
+			matched[item.Name] = true
+			reason = "excluded"
+		case f.Marked && item.Excluded:
+			reason = "marked excluded"
+		case f.MaxWeight > 0 && item.Weight > f.MaxWeight:
+			reason = fmt.Sprintf("weight %g over %g", item.Weight, f.MaxWeight)
+		case f.MinDensity > 0 && density(item) < f.MinDensity:
+			reason = fmt.Sprintf("density %.4g under %g", density(item), f.MinDensity)
+		}
+		if reason != "" {
+			filtered = append(filtered, filteredItem{Item: item, Reason: reason})
+			r.removed++
+			continue
+		}
+		r.items = append(r.items, item)
+		r.original = append(r.original, i)
+	}
+
+	var unknown []string
+	for _, name := range f.Exclude {
+		if !matched[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return r, filtered, unknown
+}
+
+// Splitting comma separated list of names, ignoring empty ones
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Checking if some item is marked excluded
+func markedExcluded(items []Item) bool {
+	for _, item := range items {
+		if item.Excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// Indices of items marked required or named, every copy of a name is required.
+// Names of no item are returned separately.
+func requiredIndices(items []Item, names []string) (required []int, unknown []string) {
+	named := map[string]bool{}
+	for _, name := range names {
+		named[name] = true
+	}
+	matched := map[string]bool{}
+	for i, item := range items {
+		if item.Required || named[item.Name] {
+			required = append(required, i)
+			matched[item.Name] = true
+		}
+	}
+	for _, name := range names {
+		if !matched[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return required, unknown
+}
+
+// Union of index lists, in order of first appearance
+func mergeIndices(a, b []int) []int {
+	seen := map[int]bool{}
+	var merged []int
+	for _, i := range append(append([]int(nil), a...), b...) {
+		if !seen[i] {
+			seen[i] = true
+			merged = append(merged, i)
+		}
+	}
+	return merged
+}
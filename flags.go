@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Flags of reading the instance, shared by subcommands
+type inputFlags struct {
+	file         *string
+	timeout      *time.Duration
+	maxSize      *int64
+	format       *string
+	strict       *bool
+	csvDelimiter *string
+	xlsxSheet    *string
+	xlsxColumns  *string
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	return &inputFlags{
+		file:         fs.String("input", "item_set_small.json", "file or HTTP(S) URL with items, - reads standard input"),
+		timeout:      fs.Duration("input-timeout", 30*time.Second, "timeout of downloading input from URL"),
+		maxSize:      fs.Int64("input-max-size", 100<<20, "max size of input downloaded from URL in bytes, 0 is unlimited"),
+		format:       fs.String("format", "", "input format: json, ndjson, csv, xlsx, yaml, toml or orlib, detected by file extension if empty"),
+		strict:       fs.Bool("strict", false, "reject unknown fields in JSON, NDJSON, YAML and TOML input"),
+		csvDelimiter: fs.String("csv-delimiter", ",", "field delimiter of CSV input and output"),
+		xlsxSheet:    fs.String("xlsx-sheet", "", "sheet of Excel input, the first one if empty"),
+		xlsxColumns:  fs.String("xlsx-columns", "", "Excel columns as name=A,weight=B,value=C (letters or header names), header names name, weight, value if empty"),
+	}
+}
+
+// Reading the instance given by flags
+func (f *inputFlags) read() (*Instance, error) {
+	delimiter := []rune(*f.csvDelimiter)
+	if len(delimiter) != 1 {
+		return nil, fmt.Errorf("CSV delimiter must be a single character, got %q", *f.csvDelimiter)
+	}
+	return readInstance(*f.file, InputOptions{
+		Format:          *f.format,
+		Strict:          *f.strict,
+		CSVDelimiter:    delimiter[0],
+		XLSXSheet:       *f.xlsxSheet,
+		XLSXColumns:     *f.xlsxColumns,
+		HTTPTimeout:     *f.timeout,
+		MaxDownloadSize: *f.maxSize,
+	})
+}
+
+// Flags the params of an instance may set. Instances may come from anywhere,
+// so they can tune the solver but never name files to write or read, nor enable
+// experimental features the operator didn't opt into.
+var instanceParams = map[string]bool{
+	"capacity":     true,
+	"max-temp":     true,
+	"min-temp":     true,
+	"cooling-rate": true,
+	"epoch-length": true,
+	"seed":         true,
+	"neighborhood": true,
+	"k":            true,
+	"fixed-k":      true,
+	"init":         true,
+	"repair":       true,
+	"solver":       true,
+	"rng":          true,
+	"kahan":        true,
+}
+
+// Using params and capacity from input data unless they are given on command line or in config
+func applyInstance(fs *flag.FlagSet, instance *Instance) error {
+	if instance.Params != nil {
+		for key := range instance.Params {
+			if !instanceParams[strings.ReplaceAll(key, "_", "-")] {
+				return fmt.Errorf("parameter %q can't be set by the instance, only solver params can", key)
+			}
+		}
+		if err := applyConfig(fs, instance.Params); err != nil {
+			return err
+		}
+	}
+	if instance.Capacity > 0 {
+		return applyConfig(fs, map[string]interface{}{"capacity": instance.Capacity})
+	}
+	return nil
+}
+
+// Flags of constraints besides the capacity, shared by subcommands
+type constraintFlags struct {
+	maxRisk       *float64
+	maxOwnerShare *float64
+	minOwnerValue *int
+	resources     *string
+	groupMode     *string
+	minItems      *int
+	maxItems      *int
+	categoryLimit *string
+	categoryCount *string
+	overflow      *float64
+}
+
+func addConstraintFlags(fs *flag.FlagSet) *constraintFlags {
+	return &constraintFlags{
+		maxRisk:       fs.Float64("max-risk", 0, "cap on total risk of selected items, 0 disables the risk budget"),
+		maxOwnerShare: fs.Float64("max-owner-share", 0, "max share of total value going to one owner, 0 disables the limit"),
+		minOwnerValue: fs.Int("min-owner-value", 0, "min value every owner has to get, 0 disables the guarantee"),
+		resources:     fs.String("resources", "", "comma separated resource capacities like volume=3,power=10, overriding those of the instance"),
+		groupMode:     fs.String("group-mode", groupAtMostOne, "items of the same group to select: at-most-one or exactly-one"),
+		minItems:      fs.Int("min-items", 0, "least number of selected items, 0 disables the bound"),
+		maxItems:      fs.Int("max-items", 0, "max number of selected items, 0 disables the bound"),
+		categoryLimit: fs.String("category-weights", "", "comma separated weight limits of categories like electronics=2, overriding those of the instance"),
+		overflow:      fs.Float64("overflow-probability", 0, "max probability of uncertain item weights exceeding the capacity, 0 disables the limit"),
+		categoryCount: fs.String("category-counts", "", "comma separated limits of item counts of categories like books=3, overriding those of the instance"),
+	}
+}
+
+// Constraints enabled by flags for capacity, with resource limits of the instance
+func (f *constraintFlags) list(instance *Instance, capacity float64) ([]Constraint, error) {
+	capacities := map[string]float64{}
+	for name, capacity := range instance.Resources {
+		capacities[name] = capacity
+	}
+	overrides, err := parseResourceCapacities(*f.resources)
+	if err != nil {
+		return nil, err
+	}
+	for name, capacity := range overrides {
+		capacities[name] = capacity
+	}
+	constraints := resourceConstraints(capacities)
+	conflicts, err := conflictConstraints(instance.Conflicts)
+	if err != nil {
+		return nil, err
+	}
+	constraints = append(constraints, conflicts...)
+	constraints = append(constraints, dependencyConstraints(instance.Items)...)
+	groups, err := groupConstraints(instance.Items, *f.groupMode)
+	if err != nil {
+		return nil, err
+	}
+	constraints = append(constraints, groups...)
+	cardinality, err := cardinalityConstraints(*f.minItems, *f.maxItems)
+	if err != nil {
+		return nil, err
+	}
+	constraints = append(constraints, cardinality...)
+	limits := map[string]CategoryLimit{}
+	for category, limit := range instance.CategoryLimits {
+		limits[category] = limit
+	}
+	if err := parseCategoryLimits(*f.categoryLimit, false, limits); err != nil {
+		return nil, err
+	}
+	if err := parseCategoryLimits(*f.categoryCount, true, limits); err != nil {
+		return nil, err
+	}
+	constraints = append(constraints, categoryConstraints(limits)...)
+	chance, err := chanceConstraints(capacity, *f.overflow)
+	if err != nil {
+		return nil, err
+	}
+	constraints = append(constraints, chance...)
+	if *f.maxRisk > 0 {
+		constraints = append(constraints, riskBudget{MaxRisk: *f.maxRisk})
+	}
+	// Owners get the value the objective gives their items
+	valuation := newOwnerValuation(instance.Curves, instance.Synergies)
+	if *f.maxOwnerShare > 0 {
+		constraints = append(constraints, ownerShareLimit{MaxShare: *f.maxOwnerShare, ownerValuation: valuation})
+	}
+	if *f.minOwnerValue > 0 {
+		constraints = append(constraints, ownerMinValue{MinValue: *f.minOwnerValue, ownerValuation: valuation})
+	}
+	return constraints, nil
+}
+
+// Verbosity flags, shared by subcommands
+type logFlags struct {
+	verbose     *bool
+	veryVerbose *bool
+	quiet       *bool
+}
+
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		verbose:     fs.Bool("v", false, "log debug diagnostics"),
+		veryVerbose: fs.Bool("vv", false, "log trace diagnostics, more than -v"),
+		quiet:       fs.Bool("quiet", false, "log errors only"),
+	}
+}
+
+// Setting up logging at the level given by flags
+func (f *logFlags) apply() {
+	level := slog.LevelInfo
+	switch {
+	case *f.quiet:
+		level = slog.LevelError
+	case *f.veryVerbose:
+		level = levelTrace
+	case *f.verbose:
+		level = slog.LevelDebug
+	}
+	setupLogging(level)
+}
+
+// Checking whether flag was given on command line
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// Parsing flags which may come after positional arguments too, returning the positional ones
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for fs.Parse(args); fs.NArg() > 0; fs.Parse(args) {
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return positional
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Results of all runs of a solve, collected as they finish
+type runCollector struct {
+	mu      sync.Mutex
+	results []Result
+}
+
+func (c *runCollector) add(result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, result)
+}
+
+// Number of runs every item was selected in
+func inclusionCounts(results []Result, n int) []int {
+	counts := make([]int, n)
+	for _, result := range results {
+		for i, included := range result.Solution {
+			counts[i] += included
+		}
+	}
+	return counts
+}
+
+// Print share of runs every item was selected in, the most certain picks first.
+// Items selected in every run or in none are robust, the ones in between are coin flips.
+func showInclusionFrequency(w io.Writer, counts []int, runs int, items []Item) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return counts[order[a]] > counts[order[b]]
+	})
+
+	fmt.Fprintf(w, "\nItem inclusion frequency over %d runs:\n", runs)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, " ITEM\tRUNS\tSHARE\t")
+	for _, i := range order {
+		share := float64(counts[i]) / float64(runs)
+		verdict := "uncertain"
+		switch {
+		case counts[i] == runs:
+			verdict = "always"
+		case counts[i] == 0:
+			verdict = "never"
+		}
+		fmt.Fprintf(tw, " %s\t%d\t%.0f%%\t%s\n", items[i].Name, counts[i], 100*share, verdict)
+	}
+	tw.Flush()
+}
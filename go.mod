@@ -1 +1,3 @@
 module knapsack
+
+go 1.22
@@ -0,0 +1,82 @@
+package main
+
+import "fmt"
+
+// Ways of choosing from item groups
+const (
+	groupAtMostOne  = "at-most-one"
+	groupExactlyOne = "exactly-one"
+)
+
+// Number of selected items of every group, groups of no selected item included
+func groupCounts(solution []int, items []Item) map[string]int {
+	counts := map[string]int{}
+	for i, item := range items {
+		if item.Group != "" {
+			counts[item.Group] += solution[i]
+		}
+	}
+	return counts
+}
+
+// At most one selected item of every group, like one of the variants of equipment
+type groupLimit struct{}
+
+func (c groupLimit) Name() string {
+	return "at most one item per group"
+}
+
+func (c groupLimit) Satisfied(solution []int, items []Item) bool {
+	for _, count := range groupCounts(solution, items) {
+		if count > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// At least one selected item of every group. Together with groupLimit it's exactly one,
+// which the empty solution search starts from can't satisfy, so it's soft.
+type groupCoverage struct{}
+
+func (c groupCoverage) Name() string {
+	return "at least one item per group"
+}
+
+func (c groupCoverage) Satisfied(solution []int, items []Item) bool {
+	return c.Violation(solution, items) == 0
+}
+
+// Share of groups without selected item
+func (c groupCoverage) Violation(solution []int, items []Item) float64 {
+	counts := groupCounts(solution, items)
+	missing := 0
+	for _, count := range counts {
+		if count == 0 {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return 0
+	}
+	return float64(missing) / float64(len(counts))
+}
+
+// Group constraints of items with mode of choosing from groups, none if no item has a group
+func groupConstraints(items []Item, mode string) ([]Constraint, error) {
+	if mode != groupAtMostOne && mode != groupExactlyOne {
+		return nil, fmt.Errorf("unknown group mode %q, expected %s or %s", mode, groupAtMostOne, groupExactlyOne)
+	}
+	grouped := false
+	for _, item := range items {
+		grouped = grouped || item.Group != ""
+	}
+	if !grouped {
+		return nil, nil
+	}
+	constraints := []Constraint{groupLimit{}}
+	if mode == groupExactlyOne {
+		constraints = append(constraints, groupCoverage{})
+	}
+	return constraints, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data of HTML and Markdown reports
+type reportData struct {
+	Title      string
+	Input      string
+	ItemCount  int
+	Capacity   float64
+	Value      int
+	Weight     float64
+	Selected   []Item
+	Params     []reportParam
+	Violation  float64
+	Seed       int64
+	Duration   time.Duration
+	Iterations int
+	Accepted   int
+	// Iteration the best solution was found at
+	BestIteration int
+}
+
+type reportParam struct {
+	Name  string
+	Value string
+}
+
+func newReportData(inputFile string, instance *Instance, params Params, result Result, order itemOrder,
+	duration time.Duration) reportData {
+	data := reportData{
+		Title:         instance.Name,
+		Input:         inputFile,
+		ItemCount:     len(instance.Items),
+		Capacity:      params.MaxWeight,
+		Value:         result.Value,
+		Weight:        result.Weight,
+		Violation:     result.Violation,
+		Seed:          result.Seed,
+		Duration:      duration,
+		Iterations:    result.Iterations,
+		Accepted:      result.Accepted,
+		BestIteration: result.BestIteration,
+	}
+	if data.Title == "" {
+		data.Title = inputFile
+	}
+	for _, i := range order.selected(result.Solution, instance.Items) {
+		data.Selected = append(data.Selected, instance.Items[i])
+	}
+
+	// Params are listed by their JSON names, as in manifests
+	encoded, _ := json.Marshal(params)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(encoded, &fields)
+	for name, value := range fields {
+		data.Params = append(data.Params, reportParam{Name: name, Value: string(value)})
+	}
+	sort.Slice(data.Params, func(i, j int) bool {
+		return data.Params[i].Name < data.Params[j].Name
+	})
+	for _, c := range params.Constraints {
+		data.Params = append(data.Params, reportParam{Name: "constraint", Value: c.Name()})
+	}
+	return data
+}
+
+// Used share of capacity in percent
+func (d reportData) Utilization() float64 {
+	if d.Capacity <= 0 {
+		return 0
+	}
+	return 100 * d.Weight / d.Capacity
+}
+
+// Share of accepted moves in percent
+func (d reportData) AcceptanceRate() float64 {
+	if d.Iterations == 0 {
+		return 0
+	}
+	return 100 * float64(d.Accepted) / float64(d.Iterations)
+}
+
+// Utilization drawn with block characters, 40 of them for full knapsack
+func (d reportData) UtilizationBar() string {
+	filled := int(d.Utilization()/100*40 + 0.5)
+	if filled > 40 {
+		filled = 40
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", 40-filled)
+}
+
+// Escaping text for Markdown table cells
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+var markdownReport = template.Must(template.New("markdown").Funcs(template.FuncMap{"cell": markdownCell}).Parse(
+	`# Knapsack report: {{.Title}}
+
+## Instance
+
+- Input: {{.Input}}
+- Items: {{.ItemCount}}
+- Capacity: {{.Capacity}}
+
+## Result
+
+- Total value: **{{.Value}}**
+- Total weight: {{printf "%.4f" .Weight}}
+- Selected items: {{len .Selected}}
+{{- if gt .Violation 0.0}}
+- Warning: no solution satisfying all constraints found, violation {{printf "%.4f" .Violation}}
+{{- end}}
+
+Utilization: ` + "`{{.UtilizationBar}}`" + ` {{printf "%.1f" .Utilization}}%
+
+| Item | Weight | Value |
+|------|-------:|------:|
+{{- range .Selected}}
+| {{cell .Name}} | {{.Weight}} | {{.Value}} |
+{{- end}}
+
+## Convergence
+
+- Iterations: {{.Iterations}}
+- Accepted moves: {{.Accepted}} ({{printf "%.1f" .AcceptanceRate}}%)
+- Best found at iteration: {{.BestIteration}}
+- Execution time: {{.Duration}}
+- Seed: {{.Seed}}
+
+## Parameters
+
+| Parameter | Value |
+|-----------|-------|
+{{- range .Params}}
+| {{.Name}} | {{cell .Value}} |
+{{- end}}
+`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Knapsack report: {{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.6em; }
+td.number { text-align: right; }
+.bar { width: 100%; height: 1.2em; background: #eee; border: 1px solid #ccc; }
+.bar div { height: 100%; background: #4a8; }
+.warning { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Knapsack report: {{.Title}}</h1>
+
+<h2>Instance</h2>
+<ul>
+<li>Input: {{.Input}}</li>
+<li>Items: {{.ItemCount}}</li>
+<li>Capacity: {{.Capacity}}</li>
+</ul>
+
+<h2>Result</h2>
+<ul>
+<li>Total value: <b>{{.Value}}</b></li>
+<li>Total weight: {{printf "%.4f" .Weight}}</li>
+<li>Selected items: {{len .Selected}}</li>
+</ul>
+{{- if gt .Violation 0.0}}
+<p class="warning">No solution satisfying all constraints found, violation {{printf "%.4f" .Violation}}</p>
+{{- end}}
+<p>Utilization {{printf "%.1f" .Utilization}}%</p>
+<div class="bar"><div style="width: {{printf "%.1f" .Utilization}}%"></div></div>
+
+<table>
+<tr><th>Item</th><th>Weight</th><th>Value</th></tr>
+{{- range .Selected}}
+<tr><td>{{.Name}}</td><td class="number">{{.Weight}}</td><td class="number">{{.Value}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Convergence</h2>
+<ul>
+<li>Iterations: {{.Iterations}}</li>
+<li>Accepted moves: {{.Accepted}} ({{printf "%.1f" .AcceptanceRate}}%)</li>
+<li>Best found at iteration: {{.BestIteration}}</li>
+<li>Execution time: {{.Duration}}</li>
+<li>Seed: {{.Seed}}</li>
+</ul>
+
+<h2>Parameters</h2>
+<table>
+<tr><th>Parameter</th><th>Value</th></tr>
+{{- range .Params}}
+<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// Writing self-contained HTML report
+func writeHTMLReport(w io.Writer, data reportData) error {
+	return htmlReport.Execute(w, data)
+}
+
+// Writing Markdown report
+func writeMarkdownReport(w io.Writer, data reportData) error {
+	return markdownReport.Execute(w, data)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options of reading items from file
+type InputOptions struct {
+	// Input format: "json", "ndjson", "csv", "xlsx", "yaml", "toml" or "orlib", detected by file extension if empty
+	Format string
+	// Rejecting unknown fields of JSON, NDJSON, YAML and TOML input
+	Strict bool
+	// Field delimiter of CSV files, comma if zero
+	CSVDelimiter rune
+	// Excel sheet name, the first sheet if empty
+	XLSXSheet string
+	// Excel column mapping like "name=A,weight=B,value=C", header names are used if empty
+	XLSXColumns string
+	// Timeout of downloading HTTP(S) input, none if zero
+	HTTPTimeout time.Duration
+	// Max size of downloaded HTTP(S) input in bytes, unlimited if zero
+	MaxDownloadSize int64
+}
+
+// Problem instance read from input
+type Instance struct {
+	Name string `json:"name,omitempty"`
+	// Capacity given by input data, zero if format has none
+	Capacity float64 `json:"capacity,omitempty"`
+	// Best known total value, zero if unknown
+	KnownOptimum int    `json:"known_optimum,omitempty"`
+	Items        []Item `json:"items"`
+	// Diminishing returns curves by item category
+	Curves map[string]Curve `json:"curves,omitempty"`
+	// Capacities of resources items consume besides weight
+	Resources map[string]float64 `json:"resources,omitempty"`
+	// Pairs of item names which must not be selected together
+	Conflicts [][]string `json:"conflicts,omitempty"`
+	// Bonus values of pairs of items selected together
+	Synergies []Synergy `json:"synergies,omitempty"`
+	// Weight and count limits by item category
+	CategoryLimits map[string]CategoryLimit `json:"category_limits,omitempty"`
+	// Solver params given by input data
+	Params map[string]interface{} `json:"-"`
+	// Hex encoded SHA-256 hash of the input
+	SHA256 string `json:"-"`
+}
+
+// Reading instance from file in configured format, "-" reads standard input
+func readInstance(filename string, opts InputOptions) (*Instance, error) {
+	format := opts.Format
+	if format == "" {
+		format = detectFormat(filename)
+	}
+
+	r, err := openInput(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Hashing input while it's read, for run manifests
+	hash := sha256.New()
+	tee := io.TeeReader(r, hash)
+	decompressed, err := decompress(tee)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	instance, err := decodeInstance(decompressed, format, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	// Reading the rest of input, so the hash covers all of it
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return nil, err
+	}
+	instance.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return instance, nil
+}
+
+// Magic numbers of compressed streams
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Decompressing input on the fly if it starts with gzip or zstd magic number.
+// Detection by content works for standard input as well as for files.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		return newZstdReader(br), nil
+	}
+	return br, nil
+}
+
+// Opening input file, "-" stands for standard input and HTTP(S) URLs are downloaded
+func openInput(filename string, opts InputOptions) (io.ReadCloser, error) {
+	if filename == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	if isURL(filename) {
+		return download(filename, opts)
+	}
+	return os.Open(filename)
+}
+
+func isURL(filename string) bool {
+	return strings.HasPrefix(filename, "http://") || strings.HasPrefix(filename, "https://")
+}
+
+// Starting download of input, response body is read as the instance is decoded
+func download(url string, opts InputOptions) (io.ReadCloser, error) {
+	client := &http.Client{Timeout: opts.HTTPTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	if opts.MaxDownloadSize > 0 && resp.ContentLength > opts.MaxDownloadSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: size %d exceeds limit of %d bytes", url, resp.ContentLength, opts.MaxDownloadSize)
+	}
+
+	body := resp.Body
+	if opts.MaxDownloadSize > 0 {
+		body = &sizeLimitedReader{ReadCloser: resp.Body, remaining: opts.MaxDownloadSize}
+	}
+	return body, nil
+}
+
+// Reader failing once more than allowed number of bytes is read,
+// unlike io.LimitedReader which silently truncates the input
+type sizeLimitedReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("input exceeds download size limit")
+	}
+	return n, err
+}
+
+// Decoding instance in given format
+func decodeInstance(r io.Reader, format string, opts InputOptions) (*Instance, error) {
+	var items []Item
+	var err error
+	switch format {
+	case "json":
+		return readInstanceFromJSON(r, opts.Strict)
+	case "csv":
+		items, err = readItemsFromCSV(r, opts.CSVDelimiter)
+	case "xlsx":
+		items, err = readItemsFromXLSX(r, opts.XLSXSheet, opts.XLSXColumns)
+	case "yaml":
+		return readInstanceFromYAML(r, opts.Strict)
+	case "toml":
+		return readInstanceFromTOML(r, opts.Strict)
+	case "ndjson":
+		items, err = readItemsFromNDJSON(r, opts.Strict)
+	case "orlib":
+		return readORLib(r)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Instance{Items: items}, nil
+}
+
+// Detecting input format by file extension, JSON is the default.
+// Compression extension is skipped, so "items.csv.gz" is CSV.
+func detectFormat(filename string) string {
+	// Query string of URLs is not a part of file name
+	if isURL(filename) {
+		if u, err := url.Parse(filename); err == nil {
+			filename = u.Path
+		}
+	}
+	name := strings.ToLower(filename)
+	for _, ext := range []string{".gz", ".zst"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	switch filepath.Ext(name) {
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".csv":
+		return "csv"
+	case ".xlsx":
+		return "xlsx"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".kp":
+		return "orlib"
+	}
+	return "json"
+}
+
+// Reading items from CSV with name, weight and value columns in the header row
+func readItemsFromCSV(r io.Reader, delimiter rune) ([]Item, error) {
+	reader := csv.NewReader(r)
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+	reader.TrimLeadingSpace = true
+
+	// Locating columns by header names, so their order does not matter
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"name", "weight", "value"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no %q column", name)
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(records))
+	for i, record := range records {
+		// Header is the first line
+		line := i + 2
+		weight, err := strconv.ParseFloat(strings.TrimSpace(record[columns["weight"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid weight: %w", line, err)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(record[columns["value"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value: %w", line, err)
+		}
+		item := Item{Name: record[columns["name"]], Weight: weight, Value: value}
+
+		// Optional columns
+		if column, ok := columns["weight_sd"]; ok && strings.TrimSpace(record[column]) != "" {
+			if item.WeightSD, err = strconv.ParseFloat(strings.TrimSpace(record[column]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid weight_sd: %w", line, err)
+			}
+		}
+		if column, ok := columns["risk"]; ok && strings.TrimSpace(record[column]) != "" {
+			if item.Risk, err = strconv.ParseFloat(strings.TrimSpace(record[column]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid risk: %w", line, err)
+			}
+		}
+		if column, ok := columns["category"]; ok {
+			item.Category = record[column]
+		}
+		if column, ok := columns["owner"]; ok {
+			item.Owner = record[column]
+		}
+		if column, ok := columns["group"]; ok {
+			item.Group = record[column]
+		}
+		if column, ok := columns["requires"]; ok {
+			item.Requires = splitRequires(record[column])
+		}
+		if column, ok := columns["required"]; ok && strings.TrimSpace(record[column]) != "" {
+			if item.Required, err = strconv.ParseBool(strings.TrimSpace(record[column])); err != nil {
+				return nil, fmt.Errorf("line %d: invalid required: %w", line, err)
+			}
+		}
+		if column, ok := columns["excluded"]; ok && strings.TrimSpace(record[column]) != "" {
+			if item.Excluded, err = strconv.ParseBool(strings.TrimSpace(record[column])); err != nil {
+				return nil, fmt.Errorf("line %d: invalid excluded: %w", line, err)
+			}
+		}
+		if column, ok := columns["quantity"]; ok && strings.TrimSpace(record[column]) != "" {
+			if item.Quantity, err = strconv.Atoi(strings.TrimSpace(record[column])); err != nil {
+				return nil, fmt.Errorf("line %d: invalid quantity: %w", line, err)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Reading items from NDJSON with one item object per line.
+// Items are decoded one by one, so the input is never held in memory as a whole.
+func readItemsFromNDJSON(r io.Reader, strict bool) ([]Item, error) {
+	var items []Item
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	for {
+		var item Item
+		err := decoder.Decode(&item)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Items are one per line, so item number is the line number as long as there are no blank lines
+			return nil, fmt.Errorf("item %d: %s", len(items)+1, describeJSONError(err))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
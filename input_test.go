@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadItemsFromCSV(t *testing.T) {
+	// Columns are found by header names in any order and case, optional ones may be empty
+	input := "Value;weight;name;category;required;quantity\n" +
+		"30; 2.5;tent;shelter;true;\n" +
+		"4;0.25;map;;;3\n"
+	items, err := readItemsFromCSV(strings.NewReader(input), ';')
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{Name: "tent", Weight: 2.5, Value: 30, Category: "shelter", Required: true},
+		{Name: "map", Weight: 0.25, Value: 4, Quantity: 3},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items %+v, want %+v", items, want)
+	}
+
+	errors := []struct {
+		input string
+		want  string
+	}{
+		{"name,weight\ntent,2\n", `no "value" column`},
+		{"name,weight,value\ntent,2,30\nmap,light,4\n", "line 3: invalid weight"},
+		{"name,weight,value\ntent,2,many\n", "line 2: invalid value"},
+		{"name,weight,value,required\ntent,2,30,maybe\n", "line 2: invalid required"},
+	}
+	for _, tt := range errors {
+		if _, err := readItemsFromCSV(strings.NewReader(tt.input), 0); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]string{
+		"items.csv":                         "csv",
+		"ITEMS.CSV.gz":                      "csv",
+		"items.jsonl.zst":                   "ndjson",
+		"items.yml":                         "yaml",
+		"p01.kp":                            "orlib",
+		"items":                             "json",
+		"https://example.com/items.csv?v=2": "csv",
+	}
+	for filename, want := range tests {
+		if format := detectFormat(filename); format != want {
+			t.Errorf("%s: format %s, want %s", filename, format, want)
+		}
+	}
+}
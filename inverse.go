@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Inverse problem: the lightest selection worth at least the target value. Leaving items out
+// of the selection is a knapsack of its own, where the weight of an item is its value and the
+// value its weight: the heaviest set of left out items worth at most all the value above the
+// target. So the usual solvers solve it, exact ones included.
+type inverseProblem struct {
+	items []Item
+	// Free items, neither required nor excluded nor worthless, and their knapsack of left out items
+	free []int
+	dual []Item
+	// Items in every selection and the value still needed on top of them
+	required []int
+	need     int
+	// Capacity of the knapsack of left out items
+	capacity float64
+}
+
+func newInverseProblem(items []Item, target int) (*inverseProblem, error) {
+	p := &inverseProblem{items: items, need: target}
+	var free []Item
+	total := 0
+	for i, item := range items {
+		switch {
+		case item.Required:
+			p.required = append(p.required, i)
+			p.need -= item.Value
+		case !item.Excluded && item.Value > 0:
+			p.free = append(p.free, i)
+			free = append(free, item)
+			total += item.Value
+		}
+	}
+	if p.need > total {
+		return nil, fmt.Errorf("items are worth %d at most, less than the target %d", total+target-p.need, target)
+	}
+
+	// Weights become values, they are whole numbers at the scale of their decimal places,
+	// or rounded to 4 decimal places
+	scale, ok := weightScale(free)
+	if !ok {
+		scale = 1e4
+		slog.Warn("Weights have more than 4 decimal places, they are rounded for the solver")
+	}
+	for _, item := range free {
+		p.dual = append(p.dual, Item{Name: item.Name, Weight: float64(item.Value), Value: int(math.Round(item.Weight * scale))})
+	}
+	if p.need > 0 {
+		p.capacity = float64(total - p.need)
+	} else {
+		// Required items reach the target, all free items are left out
+		p.capacity = float64(total)
+	}
+	return p, nil
+}
+
+// Names of constraints of the instance besides capacity and required and excluded items,
+// for solvers which can't take them, like the inverse problem whose dual knapsack has only its capacity
+func extraConstraints(instance *Instance) ([]string, error) {
+	var names []string
+	if len(instance.Curves) > 0 {
+		names = append(names, "curves")
+	}
+	if len(instance.Synergies) > 0 {
+		names = append(names, "synergies")
+	}
+	// Constraints of the instance alone, with all constraint flags at defaults
+	constraints, err := addConstraintFlags(flag.NewFlagSet("inverse", flag.ContinueOnError)).list(instance, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range constraints {
+		names = append(names, c.Name())
+	}
+	return names, nil
+}
+
+// Selection of the inverse problem from solution of left out items
+func (p *inverseProblem) selection(dual []int) []int {
+	solution := make([]int, len(p.items))
+	for _, i := range p.required {
+		solution[i] = 1
+	}
+	for j, i := range p.free {
+		if dual[j] == 0 {
+			solution[i] = 1
+		}
+	}
+	return solution
+}
+
+// Print selected items with their totals against the target value
+func showInverse(w io.Writer, solution []int, items []Item, target int) {
+	fmt.Fprintln(w, "Lightest selection reaching the target value:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, " ITEM\tWEIGHT\tVALUE")
+	count := 0
+	for i, included := range solution {
+		if included == 1 {
+			count++
+			fmt.Fprintf(tw, " %s\t%s\t%d\n", items[i].Name, formatFloat(items[i].Weight), items[i].Value)
+		}
+	}
+	value, weight := computeEnergy(solution, items)
+	fmt.Fprintf(tw, " Total (%d items)\t%s\t%d\n", count, formatFloat(weight), value)
+	fmt.Fprintf(tw, " Target\t\t%d\n", target)
+	tw.Flush()
+	fmt.Fprintf(w, "Total weight: %s\n", formatFloat(weight))
+}
+
+// Inverse subcommand: finding the lightest selection worth at least a target value,
+// like the lightest kit still covering the requirements
+func runInverse(args []string) {
+	fs := flag.NewFlagSet("inverse", flag.ExitOnError)
+	input := addInputFlags(fs)
+	target := fs.Int("min-value", 0, "least total value of the selection")
+	solverName := fs.String("solver", "auto", "algorithm: auto, exact when fast enough and annealing otherwise, annealing, exhaustive or dp")
+	maxTemp := fs.Float64("max-temp", 1000.0, "initial temperature")
+	minTemp := fs.Float64("min-temp", 0.1, "temperature to stop at")
+	coolingRate := fs.Float64("cooling-rate", 0.95, "temperature multiplier applied after every epoch")
+	epochLength := fs.Int("epoch-length", 100, "iterations per temperature before cooling down")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	timeLimit := fs.Duration("time-limit", 0, "time budget of the solve, unlimited if zero")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	if *target <= 0 {
+		invalid("-min-value must be positive", "min_value", *target)
+	}
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	// Refusing to solve nonsense input like solve does, there is no capacity to weigh items against
+	problems := validateInstance(instance, math.MaxFloat64)
+	for _, p := range problems {
+		p.log()
+	}
+	if hasErrors(problems) {
+		invalid("Invalid input", "problems", len(problems))
+	}
+	_, items := expandQuantities(instance.Items)
+	unsupported, err := extraConstraints(instance)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	if len(unsupported) > 0 {
+		invalid("Inverse problem can't take constraints besides required and excluded items", "constraints", strings.Join(unsupported, ","))
+	}
+	p, err := newInverseProblem(items, *target)
+	if err != nil {
+		exit(exitInfeasible, "Target value can't be reached", "err", err)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	params := Params{MaxWeight: p.capacity, MaxTemp: *maxTemp, MinTemp: *minTemp, CoolingRate: *coolingRate,
+		EpochLength: *epochLength, Init: "greedy", Seed: *seed}
+	var solver Solver
+	switch *solverName {
+	case "auto":
+		if solver, _, err = exactSolver(p.dual, params); err != nil {
+			solver = simulatedAnnealing
+		}
+	case "annealing":
+		solver = simulatedAnnealing
+	case "exhaustive":
+		if len(p.dual) > maxExhaustiveItems {
+			invalid("Instance too large for exhaustive solver", "max", maxExhaustiveItems, "items", len(p.dual))
+		}
+		solver = exhaustiveSolver
+	case "dp":
+		if err := dpApplicable(p.dual, params); err != nil {
+			invalid("Dynamic programming solver can't solve the instance", "err", err)
+		}
+		solver = dpSolver
+	default:
+		invalid("Unknown solver, expected auto, annealing, exhaustive or dp", "solver", *solverName)
+	}
+
+	ctx := context.Background()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+	start := time.Now()
+	result := solver(ctx, p.dual, params)
+	solution := p.selection(result.Solution)
+
+	fmt.Printf("Seed: %d\n", result.Seed)
+	showInverse(os.Stdout, solution, items, *target)
+	fmt.Printf("Execution time: %v\n", time.Since(start))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Lightest selection found through the dual knapsack matches the lightest one by enumeration
+func TestInverseLightest(t *testing.T) {
+	items := []Item{
+		{Name: "a", Weight: 2, Value: 10},
+		{Name: "b", Weight: 1.5, Value: 7},
+		{Name: "c", Weight: 1, Value: 4},
+		{Name: "d", Weight: 0.5, Value: 3},
+		{Name: "e", Weight: 3, Value: 9, Excluded: true},
+		{Name: "f", Weight: 0.25, Value: 1, Required: true},
+	}
+	for target := 1; target <= 25; target++ {
+		p, err := newInverseProblem(items, target)
+		if err != nil {
+			t.Fatalf("target %d: %v", target, err)
+		}
+		dual := dpSolver(context.Background(), p.dual, Params{MaxWeight: p.capacity})
+		solution := p.selection(dual.Solution)
+		value, weight := computeEnergy(solution, items)
+		if value < target || solution[4] == 1 || solution[5] == 0 {
+			t.Errorf("target %d: selection %v worth %d", target, solution, value)
+		}
+
+		// Enumerating selections with f and without e
+		lightest := -1.0
+		for mask := 0; mask < 16; mask++ {
+			candidate := []int{mask & 1, mask >> 1 & 1, mask >> 2 & 1, mask >> 3 & 1, 0, 1}
+			if v, w := computeEnergy(candidate, items); v >= target && (lightest < 0 || w < lightest) {
+				lightest = w
+			}
+		}
+		if weight != lightest {
+			t.Errorf("target %d: selection %v weighs %v, lightest %v", target, solution, weight, lightest)
+		}
+	}
+	if _, err := newInverseProblem(items, 26); err == nil {
+		t.Error("target above the value of all items accepted")
+	}
+}
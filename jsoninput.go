@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Options of decoding JSON instance documents
+type jsonOptions struct {
+	// Rejecting unknown fields
+	Strict bool
+	// Reporting line and column of errors, only meaningful for documents read as JSON
+	Positions bool
+}
+
+// Deserializing JSON document to instance. Items are decoded one by one,
+// so errors name the offending item and its position in the document.
+func decodeInstanceJSON(data []byte, opts jsonOptions) (*Instance, error) {
+	instance := &Instance{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if opts.Strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, jsonError(data, decoder.InputOffset(), "", err, opts)
+	}
+	switch token {
+	case json.Delim('['):
+		// Bare array of items is the original format
+		instance.Items, err = decodeJSONItems(decoder, data, opts)
+		if err != nil {
+			return nil, err
+		}
+	case json.Delim('{'):
+		// Items are decoded separately, the rest of the object is decoded into instance at once
+		rest := map[string]json.RawMessage{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, jsonError(data, decoder.InputOffset(), "", err, opts)
+			}
+			if key == "items" {
+				if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+					return nil, jsonError(data, decoder.InputOffset(), "", fmt.Errorf("items must be an array"), opts)
+				}
+				if instance.Items, err = decodeJSONItems(decoder, data, opts); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, jsonError(data, decoder.InputOffset(), "", err, opts)
+			}
+			rest[key.(string)] = value
+		}
+
+		encoded, err := json.Marshal(rest)
+		if err != nil {
+			return nil, err
+		}
+		items := instance.Items
+		restDecoder := json.NewDecoder(bytes.NewReader(encoded))
+		if opts.Strict {
+			restDecoder.DisallowUnknownFields()
+		}
+		if err := restDecoder.Decode(instance); err != nil {
+			return nil, fmt.Errorf("instance: %s", describeJSONError(err))
+		}
+		instance.Items = items
+	default:
+		return nil, fmt.Errorf("expected array of items or instance object")
+	}
+	return instance, nil
+}
+
+// Decoding array items after its opening bracket
+func decodeJSONItems(decoder *json.Decoder, data []byte, opts jsonOptions) ([]Item, error) {
+	items := []Item{}
+	for decoder.More() {
+		start := valueOffset(data, decoder.InputOffset())
+		var item Item
+		if err := decoder.Decode(&item); err != nil {
+			return nil, jsonError(data, start, fmt.Sprintf("item %d", len(items)+1), err, opts)
+		}
+		items = append(items, item)
+	}
+	// Closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return nil, jsonError(data, decoder.InputOffset(), "", err, opts)
+	}
+	return items, nil
+}
+
+// Offset of the next value after offset, past the separating comma and whitespace,
+// so that errors without position of their own point at the opening brace of the item
+func valueOffset(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && bytes.IndexByte([]byte(", \t\r\n"), data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// Wrapping decoding error with item and position in the document.
+// Offset is where decoding of the failed value started.
+func jsonError(data []byte, offset int64, item string, err error, opts jsonOptions) error {
+	// Syntax errors know their offset in the document, type errors know it
+	// relative to the value being decoded
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset += typeErr.Offset
+	}
+
+	where := item
+	if opts.Positions {
+		line, column := lineColumn(data, offset)
+		position := fmt.Sprintf("line %d, column %d", line, column)
+		if where != "" {
+			where += " (" + position + ")"
+		} else {
+			where = position
+		}
+	}
+	if where == "" {
+		return fmt.Errorf("%s", describeJSONError(err))
+	}
+	return fmt.Errorf("%s: %s", where, describeJSONError(err))
+}
+
+// Making decoding errors readable for people fixing their data
+func describeJSONError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("field %q must be %s, got %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+	return err.Error()
+}
+
+// Describing expected Go type in JSON terms
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// Converting byte offset to 1-based line and column
+func lineColumn(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONErrorPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		strict   bool
+		want     string
+	}{
+		{
+			name: "unknown field",
+			document: `[
+  {"name": "a", "weight": 1, "value": 2},
+  {"name": "b", "weight": 1, "colour": "red", "value": 2}
+]`,
+			strict: true,
+			want:   `item 2 (line 3, column 3): json: unknown field "colour"`,
+		},
+		{
+			name: "unknown field of instance items",
+			document: `{
+  "capacity": 5,
+  "items": [
+    {"name": "a", "weight": 1, "value": 2}
+    ,
+    {"name": "b", "colour": "red"}
+  ]
+}`,
+			strict: true,
+			want:   `item 2 (line 6, column 5): json: unknown field "colour"`,
+		},
+		{
+			name: "wrong type",
+			document: `[
+  {"name": "a", "weight": 1, "value": 2},
+  {"name": "b", "weight": "heavy", "value": 2}
+]`,
+			want: `item 2 (line 3, column 34): field "weight" must be a number, got string`,
+		},
+		{
+			name:     "first item",
+			document: `[{"name": "a", "value": "x"}]`,
+			want:     `item 1 (line 1, column 28): field "value" must be an integer, got string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeInstanceJSON([]byte(tt.document), jsonOptions{Strict: tt.strict, Positions: true})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
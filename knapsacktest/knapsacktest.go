@@ -0,0 +1,81 @@
+// Package knapsacktest checks invariants of simulated annealing runs,
+// for use by code extending cooling schedules and neighborhoods.
+package knapsacktest
+
+import "fmt"
+
+// One observed iteration of an annealing run
+type Event struct {
+	Iteration   int
+	Temp        float64
+	Probability float64
+	BestValue   int
+}
+
+// Checker collecting violations of run invariants:
+// temperature strictly decreasing between epochs and constant inside them,
+// acceptance probability in [0, 1] and best value never getting worse.
+type Checker struct {
+	// Iterations per temperature, one if zero
+	EpochLength int
+
+	started    bool
+	last       Event
+	violations []error
+	// Total number of violations, only the first maxViolations are kept
+	count int
+}
+
+const maxViolations = 100
+
+// Checking the next event of the run
+func (c *Checker) Observe(e Event) {
+	if e.Probability < 0 || e.Probability > 1 || e.Probability != e.Probability {
+		c.fail(e, "acceptance probability %v is outside [0, 1]", e.Probability)
+	}
+
+	if c.started {
+		epochLength := c.EpochLength
+		if epochLength < 1 {
+			epochLength = 1
+		}
+		// Temperature changes only on the first iteration of a new epoch
+		newEpoch := (e.Iteration-1)%epochLength == 0
+		if newEpoch && e.Temp >= c.last.Temp {
+			c.fail(e, "temperature %v is not lower than previous %v", e.Temp, c.last.Temp)
+		}
+		if !newEpoch && e.Temp != c.last.Temp {
+			c.fail(e, "temperature changed from %v to %v inside epoch", c.last.Temp, e.Temp)
+		}
+		if e.BestValue < c.last.BestValue {
+			c.fail(e, "best value dropped from %d to %d", c.last.BestValue, e.BestValue)
+		}
+	}
+
+	c.started = true
+	c.last = e
+}
+
+// First violations found so far
+func (c *Checker) Violations() []error {
+	return c.violations
+}
+
+// Error summarizing violations, nil if the run was correct
+func (c *Checker) Err() error {
+	switch c.count {
+	case 0:
+		return nil
+	case 1:
+		return c.violations[0]
+	}
+	return fmt.Errorf("%v (and %d more violations)", c.violations[0], c.count-1)
+}
+
+func (c *Checker) fail(e Event, format string, args ...interface{}) {
+	c.count++
+	if len(c.violations) >= maxViolations {
+		return
+	}
+	c.violations = append(c.violations, fmt.Errorf("iteration %d: "+format, append([]interface{}{e.Iteration}, args...)...))
+}
@@ -0,0 +1,64 @@
+package knapsacktest
+
+import (
+	"strings"
+	"testing"
+)
+
+// Trace of a correct run with epochs of two iterations
+func correctTrace() []Event {
+	return []Event{
+		{Iteration: 1, Temp: 100, Probability: 1, BestValue: 5},
+		{Iteration: 2, Temp: 100, Probability: 0.5, BestValue: 5},
+		{Iteration: 3, Temp: 90, Probability: 0, BestValue: 7},
+		{Iteration: 4, Temp: 90, Probability: 1, BestValue: 7},
+		{Iteration: 5, Temp: 81, Probability: 0.1, BestValue: 7},
+	}
+}
+
+func TestCheckerCorrectRun(t *testing.T) {
+	c := &Checker{EpochLength: 2}
+	for _, e := range correctTrace() {
+		c.Observe(e)
+	}
+	if err := c.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckerViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(trace []Event)
+		want   string
+	}{
+		{"probability", func(trace []Event) { trace[1].Probability = 1.5 }, "outside [0, 1]"},
+		{"cooling", func(trace []Event) { trace[2].Temp = 100 }, "is not lower"},
+		{"epoch", func(trace []Event) { trace[3].Temp = 85 }, "inside epoch"},
+		{"best", func(trace []Event) { trace[4].BestValue = 6 }, "best value dropped"},
+	}
+	for _, tt := range tests {
+		trace := correctTrace()
+		tt.change(trace)
+		c := &Checker{EpochLength: 2}
+		for _, e := range trace {
+			c.Observe(e)
+		}
+		if err := c.Err(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestCheckerSummary(t *testing.T) {
+	c := &Checker{}
+	for i := 1; i <= maxViolations+10; i++ {
+		c.Observe(Event{Iteration: i, Temp: 1, Probability: -1})
+	}
+	if len(c.Violations()) != maxViolations {
+		t.Errorf("%d violations kept, want %d", len(c.Violations()), maxViolations)
+	}
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), "more violations") {
+		t.Errorf("error %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+)
+
+// Metrics of the fitness landscape seen by a neighborhood
+type landscapeMetrics struct {
+	Walks, Steps int
+	// Autocorrelation of values one step apart on random walks
+	Autocorrelation float64
+	// Number of steps after which values are no longer correlated, longer means smoother landscape
+	CorrelationLength float64
+	// Correlation of values and Hamming distance to the best solution found,
+	// close to -1 means values lead local search towards the best solution
+	FitnessDistance float64
+	BestValue       int
+}
+
+// Sampling random walks over feasible solutions and measuring ruggedness of the landscape.
+// Walks start from repaired random solutions, infeasible moves are retried.
+func sampleLandscape(items []Item, params Params, neighborhood Neighborhood, walks, steps int) landscapeMetrics {
+	rnd := newRand(params)
+	eval := newEvaluator(items, params)
+	metrics := landscapeMetrics{Walks: walks, Steps: steps}
+
+	var values [][]float64
+	var solutions [][]int
+	// Greedy solution is the best one until walks find better
+	best := greedySolution(items, params.MaxWeight, params.Constraints)
+	bestValue, _ := eval.evaluate(best)
+
+	for w := 0; w < walks; w++ {
+		current := randomSolution(items, rnd)
+		repairSolution(current, items, params.MaxWeight)
+		walk := make([]float64, 0, steps)
+		for s := 0; s < steps; s++ {
+			// Giving up on the walk if no feasible move is found
+			var candidate []int
+			var value int
+			for try := 0; try < 100; try++ {
+				next := neighborhood.Candidate(current, rnd)
+				v, weight := eval.evaluate(next)
+				if weight <= params.MaxWeight && satisfiesHard(params.Constraints, next, items) {
+					candidate, value = next, v
+					break
+				}
+			}
+			if candidate == nil {
+				break
+			}
+			current = candidate
+			walk = append(walk, float64(value))
+			solutions = append(solutions, candidate)
+			if value > bestValue {
+				best, bestValue = candidate, value
+			}
+		}
+		values = append(values, walk)
+	}
+	metrics.BestValue = bestValue
+
+	// Autocorrelation at lag 1 pooled over all walks
+	var all []float64
+	for _, walk := range values {
+		all = append(all, walk...)
+	}
+	mean, variance := meanVariance(all)
+	covariance, pairs := 0.0, 0
+	for _, walk := range values {
+		for i := 1; i < len(walk); i++ {
+			covariance += (walk[i] - mean) * (walk[i-1] - mean)
+			pairs++
+		}
+	}
+	if pairs > 0 && variance > 0 {
+		metrics.Autocorrelation = covariance / float64(pairs) / variance
+	}
+	if rho := math.Abs(metrics.Autocorrelation); rho > 0 && rho < 1 {
+		metrics.CorrelationLength = -1 / math.Log(rho)
+	} else if rho >= 1 {
+		metrics.CorrelationLength = math.Inf(1)
+	}
+
+	// Fitness-distance correlation against the best solution found
+	distances := make([]float64, len(solutions))
+	for i, solution := range solutions {
+		for j := range solution {
+			if solution[j] != best[j] {
+				distances[i]++
+			}
+		}
+	}
+	metrics.FitnessDistance = correlation(all, distances)
+	return metrics
+}
+
+// Mean and population variance of samples
+func meanVariance(samples []float64) (mean, variance float64) {
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	for _, x := range samples {
+		mean += x
+	}
+	mean /= float64(len(samples))
+	for _, x := range samples {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, variance / float64(len(samples))
+}
+
+// Pearson correlation of two samples of the same length, 0 if either is constant
+func correlation(a, b []float64) float64 {
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	covariance := 0.0
+	for i := range a {
+		covariance += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return covariance / float64(len(a)) / math.Sqrt(varA*varB)
+}
+
+// Landscape subcommand: characterizing instance difficulty for a neighborhood
+func runLandscape(args []string) {
+	fs := flag.NewFlagSet("landscape", flag.ExitOnError)
+	input := addInputFlags(fs)
+	maxWeight := fs.Float64("capacity", 5.0, "max weight of the knapsack")
+	neighborhood := fs.String("neighborhood", "flip", "candidate moves: flip, swap, kflip, mixed or guided")
+	k := fs.Int("k", 3, "max move size of kflip neighborhood")
+	fixedK := fs.Bool("fixed-k", false, "flip exactly -k items in every kflip move instead of 1 to -k")
+	walks := fs.Int("walks", 10, "number of random walks")
+	steps := fs.Int("steps", 1000, "steps of every random walk")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	constraints := addConstraintFlags(fs)
+	featureList := fs.String("features", "", "comma separated experimental features to enable: "+featureNames())
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	if instance.Capacity > 0 {
+		if err := applyConfig(fs, map[string]interface{}{"capacity": instance.Capacity}); err != nil {
+			invalid("Error in input capacity", "err", err)
+		}
+	}
+
+	features, err := resolveFeatures(*featureList)
+	if err != nil {
+		invalid("Error in features", "err", err)
+	}
+	if err := features.require(*neighborhood); err != nil {
+		invalid("Error in algorithm params", "err", err)
+	}
+	moves, err := newNeighborhood(*neighborhood, *k, *fixedK, instance.Items, *maxWeight)
+	if err != nil {
+		invalid("Error in algorithm params", "err", err)
+	}
+
+	limits, err := constraints.list(instance, *maxWeight)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	params := Params{MaxWeight: *maxWeight, Seed: *seed, Curves: instance.Curves,
+		Synergies: instance.Synergies, Constraints: limits}
+	metrics := sampleLandscape(instance.Items, params, moves, *walks, *steps)
+
+	fmt.Printf("Random walks: %d x %d steps, %s neighborhood\n", metrics.Walks, metrics.Steps, *neighborhood)
+	fmt.Printf("Autocorrelation at lag 1: %.4f\n", metrics.Autocorrelation)
+	fmt.Printf("Correlation length: %.2f steps (longer is smoother)\n", metrics.CorrelationLength)
+	fmt.Printf("Fitness-distance correlation: %.4f (to best found, value %d; close to -1 is easy)\n",
+		metrics.FitnessDistance, metrics.BestValue)
+}
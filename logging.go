@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// Level of -vv diagnostics, below debug
+const levelTrace = slog.LevelDebug - 4
+
+// Logging diagnostics to stderr at given level, so that standard output carries results only
+func setupLogging(level slog.Level) {
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// Timestamps are noise in command line output
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if a.Key == slog.LevelKey && a.Value.Any() == levelTrace {
+				return slog.String(slog.LevelKey, "TRACE")
+			}
+			return a
+		},
+	})
+	slog.SetDefault(slog.New(handler))
+}
+
+// Logging at trace level
+func tracef(msg string, args ...any) {
+	slog.Log(context.Background(), levelTrace, msg, args...)
+}
+
+// Logging error and exiting with failure code
+func fatal(msg string, args ...any) {
+	exit(exitFailure, msg, args...)
+}
+
+// Logging error of flags or input data and exiting with invalid input code
+func invalid(msg string, args ...any) {
+	exit(exitInvalid, msg, args...)
+}
+
+// Logging error and exiting with code
+func exit(code int, msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(code)
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
+
+	"knapsack/knapsacktest"
 )
 
 // Item object containing name, weight and value
@@ -16,6 +25,77 @@ type Item struct {
 	Name   string  `json:"name"`
 	Weight float64 `json:"weight"`
 	Value  int     `json:"value"`
+	// Standard deviation of weight estimate, weight is its mean
+	WeightSD float64 `json:"weight_sd,omitempty"`
+	// Risk score counted against risk budget
+	Risk float64 `json:"risk,omitempty"`
+	// Category with optional diminishing returns curve
+	Category string `json:"category,omitempty"`
+	// Owner the item belongs to, for fairness constraints
+	Owner string `json:"owner,omitempty"`
+	// Group of alternatives, at most one or exactly one of them is selected
+	Group string `json:"group,omitempty"`
+	// Names of items the item can only be selected with
+	Requires []string `json:"requires,omitempty"`
+	// Item is in every solution, copies of items with quantity all are
+	Required bool `json:"required,omitempty"`
+	// Item is in no solution
+	Excluded bool `json:"excluded,omitempty"`
+	// Number of available copies, zero means a single one
+	Quantity int `json:"quantity,omitempty"`
+	// Consumption of resources besides weight, like volume or power
+	Resources map[string]float64 `json:"resources,omitempty"`
+	// Weight and value in every knapsack of the multiple knapsack problem,
+	// Weight and Value hold for knapsacks past their ends
+	Weights []float64 `json:"weights,omitempty"`
+	Values  []int     `json:"values,omitempty"`
+}
+
+// Simulated annealing params
+type Params struct {
+	MaxWeight   float64 `json:"max_weight"`
+	MaxTemp     float64 `json:"max_temp"`
+	MinTemp     float64 `json:"min_temp"`
+	CoolingRate float64 `json:"cooling_rate"`
+	// Iterations at every temperature, temperature is cooled once per epoch
+	EpochLength int `json:"epoch_length"`
+	// Repairing overweight candidates instead of discarding them
+	Repair bool `json:"repair"`
+	// Operator generating candidate moves, single bit flips if nil
+	Neighborhood Neighborhood `json:"-"`
+	// Initial solution: "greedy" (default), "empty" or "random"
+	Init string `json:"init"`
+	// Seed of random source, zero seeds from the clock
+	Seed int64 `json:"seed"`
+	// Random generator used with Seed: "default" or "pcg"
+	RNG string `json:"rng"`
+	// Max number of evaluated solutions kept in LRU cache, zero disables caching
+	CacheSize int `json:"cache_size"`
+	// Diminishing returns curves by item category
+	Curves map[string]Curve `json:"curves,omitempty"`
+	// Bonus values of pairs of items selected together
+	Synergies []Synergy `json:"synergies,omitempty"`
+	// Constraints besides capacity every accepted solution has to satisfy
+	Constraints []Constraint `json:"-"`
+	// Summing weights with compensated (Neumaier) summation
+	CompensatedSum bool `json:"compensated_sum"`
+	// Number of best distinct solutions to keep in result pool, 0 keeps none
+	PoolSize int `json:"pool_size,omitempty"`
+	// Min number of items solutions in the pool differ in
+	PoolDistance int `json:"pool_distance,omitempty"`
+	// Solution to start from instead of the one chosen by Init
+	Initial []int `json:"-"`
+	// Indices of items every solution has to include
+	Locked []int `json:"locked,omitempty"`
+	// Value to stop at as soon as the best solution reaches it, zero searches as long as usual
+	Target int `json:"target,omitempty"`
+	// Breaking ties between equal-value solutions by taking the lexicographically smallest one
+	Canonical bool `json:"canonical,omitempty"`
+	// Called after every iteration of the main loop
+	OnStep func(Step) `json:"-"`
+	// Caller-provided random source, takes precedence over Seed and RNG.
+	// Source is used by one run only, so concurrent runs need separate sources.
+	Source rand.Source `json:"-"`
 }
 
 // Calculating total value and total weight of given solution
@@ -43,6 +123,56 @@ func randomSolution(items []Item, rnd *rand.Rand) []int {
 	return solution
 }
 
+// Generating greedy solution: taking items in order of value density while they fit
+// and keep the constraints satisfied
+func greedySolution(items []Item, maxWeight float64, constraints []Constraint) []int {
+	return greedyExtend(make([]int, len(items)), items, maxWeight, constraints)
+}
+
+// Adding items to solution greedily, items already in it are kept
+func greedyExtend(solution []int, items []Item, maxWeight float64, constraints []Constraint) []int {
+	_, totalWeight := computeEnergy(solution, items)
+	for _, i := range densityOrder(items) {
+		if solution[i] == 0 && totalWeight+items[i].Weight <= maxWeight {
+			solution[i] = 1
+			if !satisfiesHard(constraints, solution, items) {
+				solution[i] = 0
+				continue
+			}
+			totalWeight += items[i].Weight
+		}
+	}
+	return solution
+}
+
+// Including locked items in solution
+func lockItems(solution []int, locked []int) {
+	for _, i := range locked {
+		solution[i] = 1
+	}
+}
+
+// Generating feasible initial solution according to params
+func initialSolution(items []Item, params Params, rnd *rand.Rand) []int {
+	var solution []int
+	switch {
+	// Locked items are extended greedily whatever the init mode is
+	case len(params.Locked) > 0:
+		solution = make([]int, len(items))
+		lockItems(solution, params.Locked)
+		solution = greedyExtend(solution, items, params.MaxWeight, params.Constraints)
+	case params.Init == "empty":
+		solution = make([]int, len(items))
+	case params.Init == "random":
+		solution = randomSolution(items, rnd)
+	default:
+		return greedySolution(items, params.MaxWeight, params.Constraints)
+	}
+	// Made feasible by dropping the least dense items, hard constraints included
+	repairHard(solution, items, params.MaxWeight, params.Constraints, params.Locked)
+	return solution
+}
+
 // Generating the closest candidate solution array
 func generateCandidate(solution []int, rnd *rand.Rand) []int {
 	// Initializing candidate slice with same length as solution slice
@@ -56,6 +186,85 @@ func generateCandidate(solution []int, rnd *rand.Rand) []int {
 	return candidate
 }
 
+// Repairing overweight solution by dropping included items with the lowest value density
+// until it fits into the knapsack. Solution is modified in place.
+func repairSolution(solution []int, items []Item, maxWeight float64) (totalValue int, totalWeight float64) {
+	totalValue, totalWeight = computeEnergy(solution, items)
+	if totalWeight <= maxWeight {
+		return
+	}
+
+	// Collecting indexes of included items
+	included := make([]int, 0, len(solution))
+	for i, inc := range solution {
+		if inc == 1 {
+			included = append(included, i)
+		}
+	}
+
+	// Sorting included items by value density, the worst ones first
+	sort.Slice(included, func(a, b int) bool {
+		return density(items[included[a]]) < density(items[included[b]])
+	})
+
+	// Dropping items until solution becomes feasible
+	for _, i := range included {
+		if totalWeight <= maxWeight {
+			break
+		}
+		solution[i] = 0
+		totalValue -= items[i].Value
+		totalWeight -= items[i].Weight
+	}
+	return
+}
+
+// Repairing solution which is overweight or breaks hard constraints by dropping included items
+// with the lowest value density until it's feasible, locked items are kept. Solution is modified in place.
+func repairHard(solution []int, items []Item, maxWeight float64, constraints []Constraint, locked []int) {
+	isLocked := make([]bool, len(items))
+	for _, i := range locked {
+		isLocked[i] = true
+	}
+	_, totalWeight := computeEnergy(solution, items)
+	var droppable []int
+	for i, included := range solution {
+		if included == 1 && !isLocked[i] {
+			droppable = append(droppable, i)
+		}
+	}
+	sort.SliceStable(droppable, func(a, b int) bool {
+		return density(items[droppable[a]]) < density(items[droppable[b]])
+	})
+	for _, i := range droppable {
+		if totalWeight <= maxWeight && satisfiesHard(constraints, solution, items) {
+			return
+		}
+		solution[i] = 0
+		totalWeight -= items[i].Weight
+	}
+}
+
+// Item indexes sorted by value density, the densest first
+func densityOrder(items []Item) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return density(items[order[a]]) > density(items[order[b]])
+	})
+	return order
+}
+
+// Value per weight unit of the item, weightless items are the most dense
+func density(item Item) float64 {
+	if item.Weight <= 0 {
+		return math.Inf(1)
+	}
+	return float64(item.Value) / item.Weight
+}
+
 // Returning 1 if candidate is better for sure
 // Returning random float number from 0 to 1 if candidate might be better
 func candidateIsBetter(curValue, candidateValue int, temp float64) float64 {
@@ -64,136 +273,1039 @@ func candidateIsBetter(curValue, candidateValue int, temp float64) float64 {
 	}
 
 	// Returning the base-e exponential of energy variation.
-	return math.Exp(float64(candidateValue-curValue) / temp)
+	return acceptExp(float64(candidateValue-curValue) / temp)
 }
 
-// Reading items from JSON file
-func readItemsFromJSON(filename string) ([]Item, error) {
-	// Opening the file
-	file, err := os.Open(filename)
-	// Checking if file exists
-	if err != nil {
-		return nil, err
+// Comparing solutions lexicographically, the canonical order of equal-value solutions
+func lexLess(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
 	}
-	// Closing file in the end of main function, even if error will occur
-	defer file.Close()
+	return false
+}
 
+// Reading instance from JSON: object with capacity, metadata and items,
+// or bare array of items
+func readInstanceFromJSON(r io.Reader, strict bool) (*Instance, error) {
 	// Reading file contents
-	data, err := io.ReadAll(file)
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-
-	// Deserializing JSON to array of items
-	var items []Item
-	err = json.Unmarshal(data, &items)
-	if err != nil {
-		return nil, err
-	}
-
-	return items, nil
+	return decodeInstanceJSON(data, jsonOptions{Strict: strict, Positions: true})
 }
 
-// Simulated Annealing algorithm
-func simulatedAnnealing(items []Item, maxWeight, maxTemp, minTemp, coolingRate float64) ([]int, int) {
-	// Randomizing seed for random
-	rndSrc := rand.NewSource(time.Now().UnixNano())
-	rnd := rand.New(rndSrc)
+// Simulated Annealing algorithm, stopping early with the best solution so far when context is done
+func simulatedAnnealing(ctx context.Context, items []Item, params Params) Result {
+	start := time.Now()
+	maxWeight := params.MaxWeight
+	rnd := newRand(params)
+	eval := newEvaluator(items, params)
 
-	// Generating initial random solution
-	curSolution := randomSolution(items, rnd)
-	curValue, curWeight := computeEnergy(curSolution, items)
+	// Generating feasible initial solution
+	var curSolution []int
+	if params.Initial != nil {
+		// Initial solutions of callers are made feasible the same way
+		curSolution = append([]int(nil), params.Initial...)
+		lockItems(curSolution, params.Locked)
+		repairHard(curSolution, items, maxWeight, params.Constraints, params.Locked)
+	} else {
+		curSolution = initialSolution(items, params, rnd)
+	}
+	curValue, _ := eval.evaluate(curSolution)
+	curViolation := softViolation(params.Constraints, curSolution, items)
 
-	// If weight of initial random solution exceeds maxWeight, trying to find a better solution
-	maxAttempts := 1000 //!FIXME Actually this does not solve the problem for big lists
-	attempts := 0
-	for curWeight > maxWeight && attempts < maxAttempts {
-		attempts++
-		curSolution = randomSolution(items, rnd)
-		curValue, curWeight = computeEnergy(curSolution, items)
+	// Soft constraint violation costs value of the most valuable item per unit
+	penaltyWeight := 0
+	for _, item := range items {
+		if item.Value > penaltyWeight {
+			penaltyWeight = item.Value
+		}
+	}
+	penalized := func(value int, violation float64) int {
+		return value - int(math.Ceil(violation*float64(penaltyWeight)))
+	}
+
+	neighborhood := params.Neighborhood
+	if neighborhood == nil {
+		neighborhood = flipNeighborhood{}
 	}
 
 	bestSolution := make([]int, len(curSolution))
 	copy(bestSolution, curSolution)
 	bestValue := curValue
-	temp := maxTemp
+	_, bestWeight := eval.evaluate(bestSolution)
+	bestViolation := curViolation
+	// Initial solution is the best one only if it's feasible, locked items may not let it be
+	bestFeasible := bestWeight <= maxWeight && satisfiesHard(params.Constraints, bestSolution, items)
+	var pool *solutionPool
+	if params.PoolSize > 0 {
+		pool = newSolutionPool(params.PoolSize, params.PoolDistance)
+		if curViolation == 0 {
+			_, curWeight := eval.evaluate(curSolution)
+			pool.offer(curSolution, curValue, curWeight)
+		}
+	}
+	temp := params.MaxTemp
+	epochLength := params.EpochLength
+	if epochLength < 1 {
+		epochLength = 1
+	}
 
 	// Main simulated annealing loop
-	iterations := 0
-	for temp > minTemp {
+	iterations, accepted, bestIteration := 0, 0, 0
+	for temp > params.MinTemp {
 		iterations++
 		// Generating candidate solution and calculating it's weight and value
-		candidateSolution := generateCandidate(curSolution, rnd)
-		candidateValue, candidateWeight := computeEnergy(candidateSolution, items)
+		candidateSolution := neighborhood.Candidate(curSolution, rnd)
+		// Locked items stay included whatever the move did
+		lockItems(candidateSolution, params.Locked)
+		candidateValue, candidateWeight := eval.evaluate(candidateSolution)
+
+		// Repairing candidate instead of discarding it if it's overweight
+		if params.Repair && candidateWeight > maxWeight {
+			repairSolution(candidateSolution, items, maxWeight)
+			lockItems(candidateSolution, params.Locked)
+			candidateValue, candidateWeight = eval.evaluate(candidateSolution)
+		}
+
+		step := Step{
+			Iteration:       iterations,
+			Temp:            temp,
+			Candidate:       candidateSolution,
+			CandidateValue:  candidateValue,
+			CandidateWeight: candidateWeight,
+			CurrentValue:    curValue,
+		}
 
 		// Skipping if weight of candidate solution is higher than max weight allowed
-		if candidateWeight <= maxWeight {
-			// Taking candidate solution if it's better or might be better
-			if candidateIsBetter(curValue, candidateValue, temp) > rnd.Float64() {
+		// or other constraints are violated
+		if candidateWeight <= maxWeight && satisfiesHard(params.Constraints, candidateSolution, items) {
+			step.Feasible = true
+			step.Violation = softViolation(params.Constraints, candidateSolution, items)
+			// Taking candidate solution if it's better or might be better, counting in the penalty
+			step.Probability = candidateIsBetter(penalized(curValue, curViolation),
+				penalized(candidateValue, step.Violation), temp)
+			if step.Probability > rnd.Float64() {
+				step.Accepted = true
+				accepted++
 				curSolution = candidateSolution
 				curValue = candidateValue
+				curViolation = step.Violation
 			}
 
-			// Updating best solution
-			if candidateValue > bestValue {
+			if pool != nil && step.Violation == 0 {
+				pool.offer(candidateSolution, candidateValue, candidateWeight)
+			}
+
+			// Updating best solution, only solutions satisfying soft constraints qualify
+			if step.Violation == 0 && (!bestFeasible || bestViolation > 0 || candidateValue > bestValue ||
+				params.Canonical && candidateValue == bestValue && lexLess(candidateSolution, bestSolution)) {
 				bestSolution = make([]int, len(candidateSolution))
 				copy(bestSolution, candidateSolution)
 				bestValue = candidateValue
+				bestWeight = candidateWeight
+				bestViolation = 0
+				bestFeasible = true
+				bestIteration = iterations
 			}
+		}
+		// No best value until soft constraints are satisfied
+		if bestViolation == 0 {
+			step.BestValue = bestValue
+			step.BestWeight = bestWeight
+		}
+		if params.OnStep != nil {
+			params.OnStep(step)
+		}
 
-			// Cooling down the temperature
-			temp *= coolingRate
+		// Nothing better than the target is to be found
+		if params.Target > 0 && bestViolation == 0 && bestValue >= params.Target {
+			break
+		}
+
+		// Cooling down the temperature at the end of every epoch, even if candidate did not fit
+		if iterations%epochLength == 0 {
+			temp *= params.CoolingRate
 		}
 
 		// Interrupt if there are too many iterations
 		if iterations > 1000000 {
-			fmt.Println("Too many iterations, stopping early.")
+			slog.Warn("Too many iterations, stopping early")
+			break
+		}
+
+		// Checking for cancellation from time to time, it's too slow for every iteration
+		if iterations%1024 == 0 && ctx.Err() != nil {
 			break
 		}
 	}
 
-	return bestSolution, bestValue
+	return Result{
+		Solution:      bestSolution,
+		Value:         bestValue,
+		Weight:        bestWeight,
+		Violation:     bestViolation,
+		Pool:          pool,
+		Iterations:    iterations,
+		Accepted:      accepted,
+		BestIteration: bestIteration,
+		Seed:          params.Seed,
+		Algorithm:     "simulated-annealing",
+		Duration:      time.Since(start),
+	}
 }
 
-// Print list of items included in knapsack
-func showKnapsack(solution []int, items []Item) {
-	fmt.Println("List of items included in knapsack:")
-	count := 0
-	for i, included := range solution {
-		if included == 1 {
-			count++
-			fmt.Printf(" - %s (Weight: %f, Value: %d)\n", items[i].Name, items[i].Weight, items[i].Value)
+// Number of decimals weights of items have, 6 for weights with more than 4 decimal places
+func weightDecimals(items []Item) int {
+	if scale, ok := weightScale(items); ok {
+		return int(math.Round(math.Log10(scale)))
+	}
+	return 6
+}
+
+// Print list of items included in knapsack as aligned table with totals and capacity
+// utilization, totals are highlighted when color is on. Copies are items expanded from
+// quantities, nil if there are none. Counts are copies taken of every item when solution
+// counts them, like in the unbounded problem, nil if every selected item is taken once.
+func showKnapsack(w io.Writer, selected []int, items []Item, copies *itemCopies, counts []int, capacity float64,
+	color bool) {
+	fmt.Fprintln(w, "List of items included in knapsack:")
+	// Weights are printed with as many decimals as they have, so that decimal points line up
+	decimals := weightDecimals(items)
+	weight := func(w float64) string {
+		return strconv.FormatFloat(w, 'f', decimals, 64)
+	}
+	// Copies of the same stock item are one row, with count column if there are any
+	type row struct {
+		item  Item
+		count int
+	}
+	var rows []*row
+	byStock := map[int]*row{}
+	for _, i := range selected {
+		if copies == nil {
+			count := 1
+			if counts != nil {
+				count = counts[i]
+			}
+			rows = append(rows, &row{item: items[i], count: count})
+			continue
+		}
+		if r, ok := byStock[copies.original[i]]; ok {
+			r.count++
+			continue
+		}
+		byStock[copies.original[i]] = &row{item: items[i], count: 1}
+		rows = append(rows, byStock[copies.original[i]])
+	}
+	counted := len(rows) < len(selected) || counts != nil
+	column := func(count string) string {
+		if counted {
+			return count + "\t"
 		}
+		return ""
 	}
 
-	fmt.Printf("- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -\n")
-	fmt.Printf("Total items included: %d\n", count)
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ITEM\t%sWEIGHT\tVALUE\n", column("COUNT"))
+	count, totalValue, totalWeight := 0, 0, 0.0
+	for _, r := range rows {
+		count += r.count
+		rowWeight, rowValue := float64(r.count)*r.item.Weight, r.count*r.item.Value
+		totalValue += rowValue
+		totalWeight += rowWeight
+		fmt.Fprintf(tw, "%s\t%s%s\t%d\n", r.item.Name, column(strconv.Itoa(r.count)), weight(rowWeight), rowValue)
+	}
+	fmt.Fprintf(tw, "Total (%d items)\t%s%s\t%d\n", count, column(""), weight(totalWeight), totalValue)
+	utilization := 0.0
+	if capacity > 0 {
+		utilization = 100 * totalWeight / capacity
+	}
+	fmt.Fprintf(tw, "Capacity\t%s%s\t%.1f%% used\n", column(""), weight(capacity), utilization)
+	tw.Flush()
+
+	// Coloring whole lines after aligning, escape codes would break column widths
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	for n, line := range lines {
+		switch n {
+		case 0, len(lines) - 2:
+			line = colorize(color, colorBold, line)
+		case len(lines) - 1:
+			line = colorize(color, utilizationColor(utilization), line)
+		}
+		fmt.Fprintln(w, " "+line)
+	}
 }
 
 func main() {
-	// Reading items from JSON file
-	items, err := readItemsFromJSON("item_set_small.json")
+	// Running without subcommand keeps the classic behavior: solving item_set_small.json
+	// with default params. Flags without subcommand are passed to solve.
+	setupLogging(slog.LevelInfo)
+	args := os.Args[1:]
+	command := "classic"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "classic", "solve":
+		os.Exit(runSolve(command, args))
+	case "evaluate":
+		runEvaluate(args)
+	case "convert":
+		runConvert(args)
+	case "merge":
+		runMerge(args)
+	case "landscape":
+		runLandscape(args)
+	case "session":
+		runSession(args)
+	case "verify":
+		runVerify(args)
+	case "diff":
+		runDiff(args)
+	case "multiple":
+		runMultiple(args)
+	case "pareto":
+		runPareto(args)
+	case "inverse":
+		runInverse(args)
+	case "subset-sum":
+		runSubsetSum(args)
+	default:
+		invalid("Unknown command, expected one of: classic, solve, evaluate, convert, merge, landscape, session, verify, diff, multiple, pareto, inverse, subset-sum", "command", command)
+	}
+}
+
+// Solve subcommand: reading items, running simulated annealing and printing the knapsack.
+// Classic command is solve which always reads its default input, sessions aside.
+func runSolve(command string, args []string) int {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	input := addInputFlags(fs)
+	maxWeight := fs.Float64("capacity", 5.0, "max weight of the knapsack")
+	maxTemp := fs.Float64("max-temp", 1000.0, "initial temperature")
+	minTemp := fs.Float64("min-temp", 0.1, "temperature to stop at")
+	coolingRate := fs.Float64("cooling-rate", 0.9, "temperature multiplier applied after every epoch")
+	epochLength := fs.Int("epoch-length", 1, "iterations per temperature before cooling down")
+	repair := fs.Bool("repair", false, "repair overweight candidates instead of discarding them")
+	solverName := fs.String("solver", "annealing", "algorithm: annealing, exhaustive, which enumerates all subsets of tiny instances, or dp, exact for weights with few decimals")
+	fallback := fs.Duration("fallback", 0, "time -solver exhaustive or dp gets before falling back to annealing, which also takes over instances the exact solver can't solve; no fallback if zero")
+	sortSpec := fs.String("sort", "", "order of printed and exported items: value, weight, density or name, with optional :asc or :desc; input order if empty")
+	noColor := fs.Bool("no-color", false, "never color text output, it is colored only on terminals anyway")
+	verify := fs.Bool("verify", false, "solve small instances exactly after the heuristic and report how far from the optimum it is")
+	top := fs.Int("top", 0, "print this many best distinct solutions found, all subsets are ranked with -solver exhaustive")
+	topDistance := fs.Int("top-distance", 1, "min number of items solutions of -top and -pool-export differ in, for diverse alternatives")
+	neighborhood := fs.String("neighborhood", "flip", "candidate moves: flip, swap, kflip, mixed or guided")
+	k := fs.Int("k", 3, "max move size of kflip neighborhood")
+	fixedK := fs.Bool("fixed-k", false, "flip exactly -k items in every kflip move instead of 1 to -k")
+	initMode := fs.String("init", "greedy", "initial solution: greedy, empty or random")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	cacheSize := fs.Int("cache-size", 0, "number of evaluated solutions to cache, 0 disables the cache")
+	kahan := fs.Bool("kahan", false, "sum weights with compensated summation")
+	rng := fs.String("rng", "default", "random generator: default or pcg")
+	stepMode := fs.Bool("step", false, "print every candidate, its delta, acceptance probability and the decision")
+	stepDelay := fs.Duration("step-delay", 0, "pause between iterations in step mode")
+	plotFile := fs.String("plot", "", "write value and temperature against iteration to .svg chart or .csv series")
+	progress := fs.Duration("progress", 0, "print temperature and best solution so far every this long, 0 disables")
+	progressEvery := fs.Int("progress-iterations", 0, "print temperature and best solution so far every this many iterations, 0 disables")
+	dashboardMode := fs.Bool("dashboard", false, "show live temperature, best value, acceptance rate and speed on stderr while solving")
+	checkTrace := fs.Bool("check-trace", false, "check run invariants (cooling, acceptance probability, best value) and fail on violation")
+	restarts := fs.Int("restarts", 1, "number of independent runs with derived seeds, the best one is reported")
+	retries := fs.Int("retries", 1, "max number of runs with the next seeds while no solution satisfying all constraints is found")
+	restartDistance := fs.Float64("restart-distance", 0, "start every restart at least this share of items away from earlier bests, 0 starts them independently")
+	runCount := fs.Int("runs", 0, "number of independent runs like -restarts, reporting statistics of their values besides the best one")
+	frequency := fs.Bool("frequency", false, "report share of -runs, -restarts or -portfolio runs every item was selected in")
+	portfolio := fs.String("portfolio", "", "comma separated neighborhoods to run concurrently, the best run is reported")
+	timeLimit := fs.Duration("time-limit", 0, "time budget of the whole solve, unlimited if zero")
+	workers := fs.Int("workers", 0, "max number of concurrent runs, number of CPUs if zero")
+	constraints := addConstraintFlags(fs)
+	capacitySD := fs.Float64("capacity-sd", 0, "standard deviation of normally distributed capacity, -capacity is its mean")
+	capacitySweep := fs.String("capacity-sweep", "", "solve for capacities from:to:step like 1:20:0.5, giving value against capacity as table or -output-format csv")
+	quantileList := fs.String("quantiles", "", "comma separated capacity quantiles like 0.1,0.5,0.9 to solve for, giving nested plans")
+	minDensity := fs.Float64("min-density", 0, "leave out items with lower value per weight, 0 keeps all")
+	maxItemWeight := fs.Float64("max-item-weight", 0, "leave out items heavier than this, 0 keeps all")
+	exclude := fs.String("exclude", "", "comma separated names of items to leave out")
+	require := fs.String("require", "", "comma separated names of items to include in every solution")
+	reduce := fs.Bool("reduce", false, "remove dominated items which can't be in any optimal solution before solving")
+	canonical := fs.Bool("canonical", false, "report the lexicographically smallest of equal-value solutions, for comparable results")
+	poolSize := fs.Int("pool-size", 10, "number of best distinct solutions to export with -pool-export")
+	poolExport := fs.String("pool-export", "", "export best solutions as MIP starts to files with this prefix, like pool- for pool-1.mst")
+	poolFormat := fs.String("pool-format", "mst", "format of exported solutions: mst (Gurobi) or cbc")
+	poolNames := fs.Bool("pool-names", false, "name MIP variables after items instead of x_0, x_1 and so on")
+	outputFormat := fs.String("output-format", "text", "report format: text, json, csv with selected items, html or markdown")
+	output := fs.String("output", "-", "file to write the report to, - writes standard output")
+	resultsFile := fs.String("results", "", "append result of every run to this NDJSON file as soon as it finishes, with summary next to it")
+	manifestFile := fs.String("manifest", "", "write machine-readable run manifest to this file")
+	runStore := fs.String("run-store", "", "directory keeping every run, to find previous runs of similar instances")
+	similarThreshold := fs.Float64("similar-threshold", 0.9, "share of nearly equal items for instances to be similar")
+	featureList := fs.String("features", "", "comma separated experimental features to enable: "+featureNames())
+	configFile := fs.String("config", "", "TOML file with solver params, command line flags take precedence")
+	saveSolution := fs.String("save-solution", "", "write the solution to this JSON file, to warm-start later runs from")
+	warmStart := fs.String("warm-start", "", "start from solution in JSON file written by -save-solution, -manifest or -run-store")
+	explain := fs.Int("explain", 0, "explain why this many of the most valuable excluded items are left out, -1 explains all")
+	unbounded := fs.Bool("unbounded", false, "take every item any number of times instead of at most its quantity")
+	sensitivity := fs.Bool("sensitivity", false, "report the smallest capacity increases that would change the solution and the items they bring in")
+	stopAtOptimum := fs.Bool("stop-at-optimum", true, "stop as soon as known_optimum of the instance is reached")
+	noSession := fs.Bool("no-session", false, "ignore the current session")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	// Solving the problem of the current session unless input is given, runs are kept in the session
+	var current *session
+	if command == "solve" && !*noSession {
+		var err error
+		if current, err = currentSession(); err != nil {
+			fatal("Error while opening the current session", "err", err)
+		}
+	}
+	if current != nil {
+		if !flagSet(fs, "input") {
+			slog.Info("Solving the problem of the current session instead of the default input, -no-session ignores it",
+				"session", current.Name, "problem", current.problemFile(), "default", *input.file)
+			*input.file = current.problemFile()
+		}
+		if !flagSet(fs, "run-store") {
+			*runStore = current.runsDir()
+		}
+		slog.Info("Solving in session", "session", current.Name, "input", *input.file)
+	}
+
+	// Applying params from config file
+	if *configFile != "" {
+		config, err := readParamsFromTOML(*configFile)
+		if err == nil {
+			err = applyConfig(fs, config)
+		}
+		if err != nil {
+			invalid("Error while reading config", "err", err)
+		}
+	}
+
+	// Reading items from file
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	items := instance.Items
+
+	// Using params and capacity from input data unless they are given on command line or in config
+	if err := applyInstance(fs, instance); err != nil {
+		invalid("Error in input params", "err", err)
+	}
+
+	// Notes besides the report go to standard error when it has to stay parseable
+	switch *outputFormat {
+	case "text", "json", "csv", "html", "markdown":
+	default:
+		invalid("Unknown output format, expected text, json, csv, html or markdown", "format", *outputFormat)
+	}
+	notes := io.Writer(os.Stdout)
+	if *outputFormat != "text" && *output == "-" {
+		notes = os.Stderr
+	}
+
+	slog.Debug("Instance read", "input", *input.file, "items", len(instance.Items), "capacity", *maxWeight)
+
+	order, err := parseItemOrder(*sortSpec)
 	if err != nil {
-		log.Fatalf("Error while reading the file: %v", err)
+		invalid("Error in sort order", "err", err)
+	}
+
+	// Refusing to solve nonsense input, reporting all problems at once
+	problems := validateInstance(instance, *maxWeight)
+	for _, p := range problems {
+		p.log()
+	}
+	if hasErrors(problems) {
+		invalid("Invalid input", "problems", len(problems))
+	}
+
+	// Solving for counts of items of the unbounded problem, a search of its own
+	if *unbounded {
+		params := Params{MaxWeight: *maxWeight, MaxTemp: *maxTemp, MinTemp: *minTemp, CoolingRate: *coolingRate,
+			EpochLength: *epochLength, Init: *initMode, Seed: *seed, RNG: *rng}
+		return solveUnbounded(fs, *input.file, instance, params, *restarts, *workers, *timeLimit, *outputFormat,
+			*output, order, *noColor, []rune(*input.csvDelimiter)[0])
+	}
+
+	// Solving for every copy of items with quantities, counts of copies are reported
+	var copies *itemCopies
+	copies, instance.Items = expandQuantities(instance.Items)
+	items = instance.Items
+	if copies != nil {
+		slog.Info("Items with quantities expanded into copies", "items", len(copies.stock), "copies", len(items))
+	}
+
+	// Leaving out filtered items for what-if solves, solution is mapped back to all items after solving
+	var reduced *reduction
+	filter := itemFilter{MinDensity: *minDensity, MaxWeight: *maxItemWeight, Exclude: splitNames(*exclude),
+		Marked: markedExcluded(items)}
+	if filter.active() {
+		filteredOut, filtered, unknown := filterItems(items, filter)
+		for _, name := range unknown {
+			slog.Warn("Excluded item not found", "name", name)
+		}
+		slog.Info("Filtered out items", "filtered", len(filtered), "items", len(items))
+		for _, f := range filtered {
+			if f.Item.Required {
+				invalid("Required item filtered out", "name", f.Item.Name, "reason", f.Reason)
+			}
+			slog.Info("Filtered out item", "name", f.Item.Name, "reason", f.Reason)
+		}
+		reduced = filteredOut
+		items = reduced.items
+	}
+
+	// Solving for capacity quantiles instead of a single capacity
+	var quantiles []float64
+	if *quantileList != "" {
+		if quantiles, err = parseQuantiles(*quantileList); err != nil {
+			invalid("Error in quantiles", "err", err)
+		}
+		if *capacitySD <= 0 {
+			invalid("-quantiles need -capacity-sd, the standard deviation of capacity")
+		}
+		if *reduce || *portfolio != "" || *outputFormat != "text" {
+			invalid("-quantiles can't be combined with -reduce, -portfolio or -output-format")
+		}
+		if *constraints.overflow > 0 {
+			invalid("-quantiles can't be combined with -overflow-probability, which holds for a single capacity")
+		}
+	}
+
+	// Solving for a range of capacities instead of a single capacity
+	var capacities []float64
+	if *capacitySweep != "" {
+		if capacities, err = parseCapacitySweep(*capacitySweep); err != nil {
+			invalid("Error in capacity sweep", "err", err)
+		}
+		if *reduce || *portfolio != "" || *quantileList != "" {
+			invalid("-capacity-sweep can't be combined with -reduce, -portfolio or -quantiles")
+		}
+		if *constraints.overflow > 0 {
+			invalid("-capacity-sweep can't be combined with -overflow-probability, which holds for a single capacity")
+		}
+		if *outputFormat != "text" && *outputFormat != "csv" {
+			invalid("Capacity sweep is written as text or csv only", "format", *outputFormat)
+		}
+	}
+
+	// Locked items of the session are included in every solution
+	var lockedNames []string
+	if current != nil {
+		if lockedNames, err = current.locks(); err != nil {
+			fatal("Error while reading locks", "err", err)
+		}
+		if len(lockedNames) > 0 && *reduce {
+			invalid("-reduce can't be combined with locked items, dominance reduction may remove them")
+		}
+	}
+
+	// Required items are included in every solution too, they can't be filtered out
+	required, unknown := requiredIndices(items, splitNames(*require))
+	if len(unknown) > 0 {
+		invalid("Required items not found or filtered out", "names", strings.Join(unknown, ","))
+	}
+	if len(required) > 0 && *reduce {
+		invalid("-reduce can't be combined with required items, dominance reduction may remove them")
+	}
+
+	// Removing items never needed in an optimal solution
+	if *reduce {
+		if *constraints.maxOwnerShare > 0 {
+			invalid("-reduce can't be combined with -max-owner-share, exchanging items may break the share limit")
+		}
+		if *constraints.minItems > 0 {
+			invalid("-reduce can't be combined with -min-items, removed items may be needed to reach the count")
+		}
+		if len(instance.Synergies) > 0 {
+			invalid("-reduce can't be combined with synergies, a dominated item may be worth more with its pair")
+		}
+		if len(instance.Conflicts) > 0 {
+			invalid("-reduce can't be combined with conflicts, exchanging items may bring conflicting ones together")
+		}
+		if dependencyConstraints(items) != nil {
+			invalid("-reduce can't be combined with item dependencies (requires), removing an item may break them")
+		}
+		dominance := reduceDominated(items, *maxWeight)
+		slog.Info("Dominance reduction removed items", "removed", dominance.removed, "items", dominance.total)
+		reduced = reduced.then(dominance)
+		items = reduced.items
 	}
 
 	// Algorithm params
-	maxWeight := 5.0
-	maxTemp := 1000.0
-	minTemp := 0.1
-	coolingRate := 0.9
+	params := Params{
+		MaxWeight:      *maxWeight,
+		MaxTemp:        *maxTemp,
+		MinTemp:        *minTemp,
+		CoolingRate:    *coolingRate,
+		EpochLength:    *epochLength,
+		Repair:         *repair,
+		Init:           *initMode,
+		Seed:           *seed,
+		RNG:            *rng,
+		CacheSize:      *cacheSize,
+		CompensatedSum: *kahan,
+		Canonical:      *canonical,
+	}
+
+	params.Curves = instance.Curves
+	params.Synergies = instance.Synergies
+	if *poolExport != "" {
+		if *poolFormat != "mst" && *poolFormat != "cbc" {
+			invalid("Unknown pool format, expected mst or cbc", "format", *poolFormat)
+		}
+		params.PoolSize = *poolSize
+	}
+	if *top > params.PoolSize {
+		params.PoolSize = *top
+	}
+	if *topDistance > 1 {
+		params.PoolDistance = *topDistance
+	}
+	// Known optimum holds for the capacity of the instance only
+	knownOptimum := 0
+	if instance.KnownOptimum > 0 && (instance.Capacity == 0 || instance.Capacity == params.MaxWeight) {
+		knownOptimum = instance.KnownOptimum
+	}
+	if *stopAtOptimum && *quantileList == "" && *capacitySweep == "" {
+		params.Target = knownOptimum
+	}
+	if params.Constraints, err = constraints.list(instance, params.MaxWeight); err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	if len(lockedNames) > 0 {
+		var unknown []string
+		params.Locked, unknown = lockedIndices(items, lockedNames)
+		for _, name := range unknown {
+			slog.Warn("Locked item not found or filtered out", "name", name)
+		}
+	}
+	params.Locked = mergeIndices(params.Locked, required)
+	checkFeasible(items, params)
+
+	// Resolving time-based seed here, so it can be printed and the run repeated,
+	// warm start drops items with it too
+	if params.Seed == 0 {
+		params.Seed = time.Now().UnixNano()
+	}
+	if *rng != "default" && *rng != "pcg" {
+		invalid("Unknown random generator", "rng", *rng)
+	}
+
+	// Starting from earlier solution, matched by item names, made to fit and filled up greedily
+	if *warmStart != "" {
+		saved, err := readSolution(*warmStart)
+		if err != nil {
+			invalid("Error while reading warm start", "err", err)
+		}
+		initial, unknown, err := saved.apply(instance.Items)
+		if err != nil {
+			invalid("Error in warm start", "err", err)
+		}
+		for _, name := range unknown {
+			slog.Warn("Warm start item not found", "name", name)
+		}
+		if reduced != nil {
+			full := initial
+			initial = make([]int, len(items))
+			for i, original := range reduced.original {
+				initial[i] = full[original]
+			}
+		}
+		lockItems(initial, params.Locked)
+		dropRandomly(initial, items, params.MaxWeight, params.Locked, newRand(params))
+		repairHard(initial, items, params.MaxWeight, params.Constraints, params.Locked)
+		params.Initial = greedyExtend(initial, items, params.MaxWeight, params.Constraints)
+		value, weight := computeEnergy(params.Initial, items)
+		slog.Info("Warm-starting", "file", *warmStart, "saved_value", saved.Value, "value", value, "weight", weight)
+	}
+
+	features, err := resolveFeatures(*featureList)
+	if err != nil {
+		invalid("Error in features", "err", err)
+	}
+	if err := features.require(*neighborhood); err != nil {
+		invalid("Error in algorithm params", "err", err)
+	}
+	params.Neighborhood, err = newNeighborhood(*neighborhood, *k, *fixedK, items, params.MaxWeight)
+	if err != nil {
+		invalid("Error in algorithm params", "err", err)
+	}
+
+	if *runCount > 0 {
+		if flagSet(fs, "restarts") {
+			invalid("-runs and -restarts both set the number of runs, use one of them")
+		}
+		*restarts = *runCount
+	}
+
+	// Step hooks are not safe for concurrent runs and their traces would interleave
+	progressMode := *progress > 0 || *progressEvery > 0
+	if (*stepMode || *checkTrace || *plotFile != "" || *dashboardMode || progressMode) &&
+		(*restarts > 1 || *portfolio != "" || *quantileList != "" || *capacitySweep != "") {
+		invalid("-step, -check-trace, -plot, -dashboard and -progress need a single run, not -restarts, -portfolio, -quantiles or -capacity-sweep")
+	}
+	if err := features.require("fallback"); err != nil && *fallback > 0 {
+		invalid("Error in algorithm params", "err", err)
+	}
+	if *fallback > 0 && (*solverName == "annealing" || *quantileList != "" || *capacitySweep != "") {
+		invalid("-fallback needs -solver exhaustive or dp and can't be combined with -quantiles or -capacity-sweep")
+	}
+	switch *solverName {
+	case "annealing":
+	case "exhaustive":
+		if len(items) > maxExhaustiveItems && *fallback > 0 {
+			slog.Warn("Instance too large for exhaustive solver, falling back to annealing", "max", maxExhaustiveItems, "items", len(items))
+			*solverName, *fallback = "annealing", 0
+		} else if len(items) > maxExhaustiveItems {
+			invalid("Instance too large for exhaustive solver", "max", maxExhaustiveItems, "items", len(items))
+		}
+		if *restarts > 1 || *portfolio != "" {
+			invalid("Exhaustive solver is deterministic, -restarts and -portfolio make no sense with it")
+		}
+	case "dp":
+		// Table has to fit the largest capacity of the sweep
+		largest := params
+		if len(capacities) > 0 {
+			largest.MaxWeight = math.Max(largest.MaxWeight, capacities[len(capacities)-1])
+		}
+		if err := dpApplicable(items, largest); err != nil && *fallback > 0 {
+			slog.Warn("Dynamic programming solver can't solve the instance, falling back to annealing", "err", err)
+			*solverName, *fallback = "annealing", 0
+		} else if err != nil {
+			invalid("Dynamic programming solver can't solve the instance", "err", err)
+		}
+		if *top > 0 || *restarts > 1 || *portfolio != "" {
+			invalid("Dynamic programming solver is deterministic and finds one solution, -top, -restarts and -portfolio make no sense with it")
+		}
+	default:
+		invalid("Unknown solver, expected annealing, exhaustive or dp", "solver", *solverName)
+	}
+	if *frequency && *restarts <= 1 && *portfolio == "" {
+		invalid("-frequency needs several runs, use it with -runs, -restarts or -portfolio")
+	}
+	if *restartDistance < 0 || *restartDistance > 1 {
+		invalid("-restart-distance must be between 0 and 1")
+	}
+	if *stepMode && *dashboardMode {
+		invalid("-step and -dashboard can't be used together")
+	}
+	if *stepMode {
+		params.OnStep = stepPrinter(notes, *stepDelay)
+	}
+	checker := &knapsacktest.Checker{EpochLength: params.EpochLength}
+	if *checkTrace {
+		params.OnStep = chainSteps(params.OnStep, traceChecker(checker))
+	}
+	recorder := newConvergenceRecorder(2000)
+	if *plotFile != "" {
+		params.OnStep = chainSteps(params.OnStep, recorder.observe)
+	}
+	if progressMode {
+		params.OnStep = chainSteps(params.OnStep, progressPrinter(os.Stderr, *progress, *progressEvery))
+	}
+	var live *dashboard
+	if *dashboardMode {
+		live = newDashboard(os.Stderr, 200*time.Millisecond)
+		params.OnStep = chainSteps(params.OnStep, live.observe)
+	}
+
+	// Pointing out previous runs of nearly the same instance, their solutions are good starting points
+	if *runStore != "" {
+		runs, err := loadRuns(*runStore)
+		if err != nil {
+			fatal("Error while reading the run store", "err", err)
+		}
+		for i, similar := range findSimilarRuns(runs, instance.Items, params.MaxWeight, *similarThreshold) {
+			if i == 3 {
+				break
+			}
+			slog.Info("Similar instance solved before, consider warm-starting from its solution", "file", similar.Run.file,
+				"similarity", math.Round(similar.Similarity*100)/100, "value", similar.Run.Manifest.Result.Value)
+		}
+	}
 
 	// Record script start time
 	start := time.Now()
 
-	// Run simulated annealing algorithm
-	bestSolution, bestValue := simulatedAnnealing(items, maxWeight, maxTemp, minTemp, coolingRate)
-	fmt.Printf("Best solution: %v\n", bestSolution)
-	showKnapsack(bestSolution, items)
-	fmt.Printf("Total value: %d\n", bestValue)
-
-	// Script execution time calculation
+	// Run simulated annealing algorithm, restarts and portfolios run concurrently
+	orchestrator := &Orchestrator{Budget: *timeLimit, Workers: *workers, Canonical: *canonical}
+	if *resultsFile != "" {
+		results, err := openResultLog(*resultsFile)
+		if err != nil {
+			fatal("Error while opening results file", "err", err)
+		}
+		defer results.Close()
+		orchestrator.OnResult = func(_ Params, result Result) {
+			if reduced != nil {
+				result = reduced.expandResult(result)
+			}
+			if err := results.record(result); err != nil {
+				slog.Warn("Run result not saved", "seed", result.Seed, "err", err)
+			}
+		}
+	}
+	// Keeping every run for statistics and to tell how often items were selected
+	runs := &runCollector{}
+	if *frequency || *runCount > 0 {
+		previous := orchestrator.OnResult
+		orchestrator.OnResult = func(params Params, result Result) {
+			if previous != nil {
+				previous(params, result)
+			}
+			if reduced != nil {
+				result = reduced.expandResult(result)
+			}
+			runs.add(result)
+		}
+	}
+	algorithm := simulatedAnnealing
+	switch *solverName {
+	case "exhaustive":
+		algorithm = exhaustiveSolver
+	case "dp":
+		algorithm = dpSolver
+	}
+	wrap := func(algorithm Solver) Solver {
+		return Chain(algorithm,
+			WrapWithValidation(func(err error) { exit(exitInfeasible, "Invalid solver result", "err", err) }),
+			WrapWithRetry(*retries), WrapWithLogging(slog.Default()), WrapWithTiming)
+	}
+	solver := wrap(algorithm)
+	ctx := context.Background()
+	solving, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Other runs can't beat a run reaching the target
+	if params.Target > 0 {
+		previous := orchestrator.OnResult
+		orchestrator.OnResult = func(params Params, result Result) {
+			if previous != nil {
+				previous(params, result)
+			}
+			if result.Violation == 0 && result.Value >= params.Target {
+				cancel()
+			}
+		}
+	}
+	var result Result
+	switch {
+	case *quantileList != "":
+		plans := solveQuantiles(solving, orchestrator, items, params, *restarts, quantiles, *capacitySD, solver)
+		for i := range plans {
+			if reduced != nil {
+				plans[i].Result = reduced.expandResult(plans[i].Result)
+				for j, added := range plans[i].Added {
+					plans[i].Added[j] = reduced.original[added]
+				}
+			}
+		}
+		report, closeReport, err := createReport(*output)
+		if err != nil {
+			fatal("Error while writing the report", "err", err)
+		}
+		fmt.Fprintf(report, "Seed: %d\n", params.Seed)
+		showQuantilePlans(report, plans, instance.Items)
+		if err := closeReport(); err != nil {
+			fatal("Error while writing the report", "err", err)
+		}
+		return exitOK
+	case *capacitySweep != "":
+		points := solveSweep(solving, orchestrator, items, params, *restarts, capacities, solver)
+		report, closeReport, err := createReport(*output)
+		if err != nil {
+			fatal("Error while writing the capacity sweep", "err", err)
+		}
+		if *outputFormat == "csv" {
+			err = writeSweepCSV(report, points, items, []rune(*input.csvDelimiter)[0])
+		} else {
+			fmt.Fprintf(report, "Seed: %d\n", params.Seed)
+			showSweep(report, points, items)
+		}
+		if closeErr := closeReport(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fatal("Error while writing the capacity sweep", "err", err)
+		}
+		return exitOK
+	case *portfolio != "":
+		var configs []Params
+		for _, name := range strings.Split(*portfolio, ",") {
+			config := params
+			if err := features.require(name); err != nil {
+				invalid("Error in portfolio", "err", err)
+			}
+			if config.Neighborhood, err = newNeighborhood(name, *k, *fixedK, items, params.MaxWeight); err != nil {
+				invalid("Error in portfolio", "err", err)
+			}
+			configs = append(configs, config)
+		}
+		result = orchestrator.Portfolio(solving, items, configs, solver)
+	case *restartDistance > 0 && *restarts > 1:
+		minDistance := int(math.Ceil(*restartDistance * float64(len(items))))
+		result = orchestrator.DiverseRestarts(solving, items, params, *restarts, minDistance, solver)
+	case *fallback > 0:
+		result = orchestrator.Fallback(solving, items, params, Stage{solver, *fallback}, Stage{wrap(simulatedAnnealing), 0})
+	default:
+		result = orchestrator.Restarts(solving, items, params, *restarts, solver)
+	}
+	if live != nil {
+		live.finish()
+	}
+	// Bound of the solved items holds for the whole instance, reduction keeps an optimal solution
+	bound := upperBound(items, params)
+	var options []capacityOption
+	solved := items
+	if *sensitivity {
+		options = capacityOptions(result.Solution, items, params)
+	}
+	var exclusions []exclusion
+	if *explain != 0 {
+		exclusions = explainExclusions(result.Solution, items, params, *explain)
+	}
 	duration := time.Since(start)
-	fmt.Printf("Execution time: %v\n", duration)
-	fmt.Printf("-------------------------------------------------------------")
+
+	// Checking the heuristic against the exact optimum when it is cheap enough, on the solved
+	// items, which locked items of params index
+	var verified *verification
+	if *verify {
+		if verified, err = verifyResult(ctx, items, params); err != nil {
+			slog.Info("Instance not verified", "reason", err)
+		}
+	}
+	if reduced != nil {
+		result = reduced.expandResult(result)
+		items = instance.Items
+	}
+	if copies != nil {
+		result.Counts = copies.counts(result.Solution)
+	}
+
+	// Writing the report, in text or JSON for downstream tooling
+	report := io.Writer(os.Stdout)
+	var reportFile *os.File
+	if *output != "-" {
+		if reportFile, err = os.Create(*output); err != nil {
+			fatal("Error while writing the report", "err", err)
+		}
+		report = reportFile
+	}
+	switch *outputFormat {
+	case "json":
+		jsonReport := newJSONReport(*input.file, instance, params, result, order, duration)
+		jsonReport.UpperBound, jsonReport.Gap = bound, boundGap(result.Value, bound)
+		jsonReport.Features = features.List()
+		err = writeJSONReport(report, jsonReport)
+	case "csv":
+		err = writeCSVReport(report, result, items, order, []rune(*input.csvDelimiter)[0])
+	case "html":
+		err = writeHTMLReport(report, newReportData(*input.file, instance, params, result, order, duration))
+	case "markdown":
+		err = writeMarkdownReport(report, newReportData(*input.file, instance, params, result, order, duration))
+	default:
+		writeTextReport(report, result, items, copies, order, params.MaxWeight,
+			newOwnerValuation(params.Curves, params.Synergies), duration, colorEnabled(report, *noColor))
+	}
+	if reportFile != nil {
+		if closeErr := reportFile.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fatal("Error while writing the report", "err", err)
+	}
+
+	if *plotFile != "" {
+		if err := writePlot(*plotFile, recorder.series()); err != nil {
+			fatal("Error while writing the plot", "err", err)
+		}
+	}
+
+	showResources(notes, result.Solution, items, params.Constraints)
+	showCategoryLimits(notes, result.Solution, items, params.Constraints)
+	showOverflow(notes, result.Solution, items, params.Constraints)
+	showSynergies(notes, result.Solution, items, params.Synergies)
+	showBound(notes, bound, result)
+	if knownOptimum > 0 {
+		showKnownOptimum(notes, knownOptimum, result)
+	}
+	if *sensitivity {
+		showSensitivity(notes, options, solved, 5)
+	}
+	showExclusions(notes, exclusions, solved)
+
+	if *top > 0 && result.Pool != nil {
+		showRanking(notes, result.Pool, items)
+	}
+
+	if *runCount > 0 {
+		showRunStatistics(notes, newRunStatistics(runs.results))
+	}
+	if *frequency {
+		showInclusionFrequency(notes, inclusionCounts(runs.results, len(items)), len(runs.results), items)
+	}
+
+	if verified != nil {
+		showVerification(notes, verified, result)
+	}
+
+	if *saveSolution != "" {
+		if err := writeSolution(*saveSolution, newSavedSolution(*input.file, params.MaxWeight, result, items)); err != nil {
+			fatal("Error while saving the solution", "err", err)
+		}
+	}
+
+	if *poolExport != "" && result.Pool != nil {
+		files, err := exportPool(result.Pool, items, *poolExport, *poolFormat, *poolNames)
+		if err != nil {
+			fatal("Error while exporting solution pool", "err", err)
+		}
+		slog.Info("Exported solution pool", "solutions", len(files), "files", strings.Join(files, ","))
+	}
+
+	if err := checker.Err(); err != nil {
+		exit(exitInvariant, "Run invariant violated", "err", err)
+	}
+
+	// Writing run manifest
+	if *manifestFile != "" || *runStore != "" {
+		manifest, err := newManifest(*input.file, instance, params, *neighborhood, features, result, start, duration)
+		if err != nil {
+			fatal("Error while creating run manifest", "err", err)
+		}
+		if *manifestFile != "" {
+			if err := writeManifest(*manifestFile, manifest); err != nil {
+				fatal("Error while writing run manifest", "err", err)
+			}
+		}
+		if *runStore != "" {
+			run, err := saveRun(*runStore, manifest, params.MaxWeight, instance.Items)
+			if err != nil {
+				fatal("Error while saving run to the store", "err", err)
+			}
+			if current != nil {
+				entry := historyEntry{Time: start, Input: *input.file, Seed: result.Seed, Value: result.Value,
+					Weight: result.Weight, Locked: lockedNames}
+				entry.Run, _ = filepath.Rel(current.dir, run)
+				if err := current.record(entry); err != nil {
+					fatal("Error while writing session history", "err", err)
+				}
+			}
+		}
+	}
+
+	// Telling scripts why no solution satisfying all constraints was found
+	if result.Violation > 0 {
+		if *timeLimit > 0 && duration >= *timeLimit {
+			return exitTimeout
+		}
+		return exitUnsatisfied
+	}
+	return exitOK
 }
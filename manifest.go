@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// Machine-readable record of a run with everything needed to reproduce and audit it
+type Manifest struct {
+	Input        string         `json:"input"`
+	InputSHA256  string         `json:"input_sha256"`
+	Algorithm    string         `json:"algorithm"`
+	Version      string         `json:"solver_version"`
+	Neighborhood string         `json:"neighborhood"`
+	Features     []string       `json:"features"`
+	Params       Params         `json:"params"`
+	Constraints  []string       `json:"constraints,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	Duration     string         `json:"duration"`
+	Result       ManifestResult `json:"result"`
+}
+
+// Final result of a run
+type ManifestResult struct {
+	Value    int      `json:"value"`
+	Weight   float64  `json:"weight"`
+	Solution []int    `json:"solution"`
+	Items    []string `json:"items"`
+}
+
+// Creating manifest of finished run
+func newManifest(inputFile string, instance *Instance, params Params, neighborhood string, features Features,
+	result Result, start time.Time, duration time.Duration) (*Manifest, error) {
+	items := instance.Items
+	solution := result.Solution
+	var names []string
+	for i, included := range solution {
+		if included == 1 {
+			names = append(names, items[i].Name)
+		}
+	}
+
+	var constraints []string
+	for _, c := range params.Constraints {
+		constraints = append(constraints, c.Name())
+	}
+
+	return &Manifest{
+		Input:        inputFile,
+		InputSHA256:  instance.SHA256,
+		Algorithm:    result.Algorithm,
+		Version:      solverVersion(),
+		Neighborhood: neighborhood,
+		Features:     features.List(),
+		Params:       params,
+		Constraints:  constraints,
+		StartedAt:    start,
+		Duration:     duration.String(),
+		Result: ManifestResult{
+			Value:    result.Value,
+			Weight:   result.Weight,
+			Solution: solution,
+			Items:    names,
+		},
+	}, nil
+}
+
+// Writing manifest as indented JSON
+func writeManifest(filename string, manifest *Manifest) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	// Constraint names contain comparison signs
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(manifest)
+}
+
+// Version of the solver binary taken from build info, with VCS revision if known
+func solverVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			version += "+" + setting.Value
+		}
+	}
+	return version
+}
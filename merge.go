@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Ways of handling identical items found in several files
+const (
+	dedupeNone = "none"
+	dedupeDrop = "drop"
+	dedupeSum  = "sum"
+)
+
+// Merging instances into one: items are concatenated in order, identical items
+// are kept, dropped or summed into quantities by dedupe mode. Capacity and curves
+// are taken from the first instance having them, conflicting ones are reported.
+func mergeInstances(instances []*Instance, dedupe string) (*Instance, []string, error) {
+	if dedupe != dedupeNone && dedupe != dedupeDrop && dedupe != dedupeSum {
+		return nil, nil, fmt.Errorf("unknown dedupe mode %q, expected none, drop or sum", dedupe)
+	}
+
+	merged := &Instance{Items: []Item{}}
+	var warnings []string
+	// Position of first copy of every item, keyed by JSON of the item with quantity left out,
+	// as resources make items incomparable
+	seen := map[string]int{}
+	conflicts := map[string]bool{}
+	synergies := map[string]int{}
+	for n, instance := range instances {
+		// Conflicts of all files hold, each pair once
+		for _, pair := range instance.Conflicts {
+			if key := strings.Join(pair, "\x00"); !conflicts[key] {
+				conflicts[key] = true
+				merged.Conflicts = append(merged.Conflicts, pair)
+			}
+		}
+		// Synergies of all files hold, a pair with differing bonuses keeps the first one
+		for _, synergy := range instance.Synergies {
+			key := strings.Join(synergy.Items, "\x00")
+			if existing, ok := synergies[key]; !ok {
+				synergies[key] = synergy.Bonus
+				merged.Synergies = append(merged.Synergies, synergy)
+			} else if existing != synergy.Bonus {
+				warnings = append(warnings, fmt.Sprintf("file %d: bonus %d of synergy %s differs from %d, keeping the first one",
+					n+1, synergy.Bonus, formatPair(synergy.Items), existing))
+			}
+		}
+		if instance.Capacity > 0 {
+			if merged.Capacity == 0 {
+				merged.Capacity = instance.Capacity
+			} else if merged.Capacity != instance.Capacity {
+				warnings = append(warnings, fmt.Sprintf("file %d: capacity %g differs from %g, keeping the first one", n+1, instance.Capacity, merged.Capacity))
+			}
+		}
+		for _, resource := range sortedResources(instance.Resources) {
+			capacity := instance.Resources[resource]
+			if merged.Resources == nil {
+				merged.Resources = map[string]float64{}
+			}
+			if existing, ok := merged.Resources[resource]; !ok {
+				merged.Resources[resource] = capacity
+			} else if existing != capacity {
+				warnings = append(warnings, fmt.Sprintf("file %d: capacity %g of resource %q differs from %g, keeping the first one", n+1, capacity, resource, existing))
+			}
+		}
+		for _, category := range sortedLimitCategories(instance.CategoryLimits) {
+			limit := instance.CategoryLimits[category]
+			if merged.CategoryLimits == nil {
+				merged.CategoryLimits = map[string]CategoryLimit{}
+			}
+			if existing, ok := merged.CategoryLimits[category]; !ok {
+				merged.CategoryLimits[category] = limit
+			} else if existing != limit {
+				warnings = append(warnings, fmt.Sprintf("file %d: limits of category %q differ, keeping the first one", n+1, category))
+			}
+		}
+		for _, category := range sortedCategories(instance.Curves) {
+			curve := instance.Curves[category]
+			if merged.Curves == nil {
+				merged.Curves = map[string]Curve{}
+			}
+			if existing, ok := merged.Curves[category]; !ok {
+				merged.Curves[category] = curve
+			} else if !reflect.DeepEqual(existing, curve) {
+				warnings = append(warnings, fmt.Sprintf("file %d: curve of category %q differs, keeping the first one", n+1, category))
+			}
+		}
+
+		for _, item := range instance.Items {
+			unquantified := item
+			unquantified.Quantity = 0
+			encoded, err := json.Marshal(unquantified)
+			if err != nil {
+				return nil, nil, err
+			}
+			key := string(encoded)
+			first, duplicate := seen[key]
+			switch {
+			case !duplicate || dedupe == dedupeNone:
+				seen[key] = len(merged.Items)
+				merged.Items = append(merged.Items, item)
+			case dedupe == dedupeSum:
+				merged.Items[first].Quantity = copies(merged.Items[first]) + copies(item)
+			}
+		}
+	}
+	return merged, warnings, nil
+}
+
+// Number of copies of the item, missing quantity means one
+func copies(item Item) int {
+	if item.Quantity == 0 {
+		return 1
+	}
+	return item.Quantity
+}
+
+// Merge subcommand: concatenating instance files given as arguments into one instance
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	strict := fs.Bool("strict", false, "reject unknown fields in JSON, NDJSON, YAML and TOML input")
+	csvDelimiter := fs.String("csv-delimiter", ",", "field delimiter of CSV input and output")
+	dedupe := fs.String("dedupe", dedupeNone, "identical items: none keeps all, drop keeps the first one, sum counts them in quantity of the first one")
+	output := fs.String("output", "-", "file to write merged instance to, - writes standard output")
+	outputFormat := fs.String("output-format", "", "output format: json, ndjson, csv, yaml, toml or orlib, detected by output file extension if empty")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	if fs.NArg() == 0 {
+		invalid("Usage: merge [flags] file...")
+	}
+	delimiter := []rune(*csvDelimiter)
+	if len(delimiter) != 1 {
+		invalid("CSV delimiter must be a single character", "delimiter", *csvDelimiter)
+	}
+	format := *outputFormat
+	if format == "" {
+		if *output == "-" {
+			invalid("-output-format is required when writing standard output")
+		}
+		format = detectFormat(*output)
+	}
+
+	// Formats are detected for every file separately, so exports of different tools can be mixed
+	var instances []*Instance
+	for _, filename := range fs.Args() {
+		instance, err := readInstance(filename, InputOptions{Strict: *strict, CSVDelimiter: delimiter[0]})
+		if err != nil {
+			invalid("Error while reading the file", "file", filename, "err", err)
+		}
+		instances = append(instances, instance)
+	}
+
+	merged, warnings, err := mergeInstances(instances, *dedupe)
+	if err != nil {
+		invalid("Error while merging", "err", err)
+	}
+	for _, warning := range warnings {
+		slog.Warn(warning)
+	}
+	total := 0
+	for _, instance := range instances {
+		total += len(instance.Items)
+	}
+	slog.Info("Merged items", "items", total, "files", len(instances), "merged", len(merged.Items))
+
+	for _, field := range droppedFields(merged, format) {
+		slog.Warn("Field can't be written in output format, dropping it", "field", field, "format", format)
+	}
+	if err := writeInstanceFile(*output, merged, format, delimiter[0]); err != nil {
+		fatal("Error while writing the file", "err", err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeInstances(t *testing.T) {
+	first := &Instance{
+		Capacity:  5,
+		Items:     []Item{{Name: "tent", Weight: 2, Value: 30}, {Name: "map", Weight: 0.25, Value: 4, Quantity: 2}},
+		Conflicts: [][]string{{"tent", "map"}},
+	}
+	second := &Instance{
+		Capacity:  6,
+		Items:     []Item{{Name: "map", Weight: 0.25, Value: 4}, {Name: "tent", Weight: 2.5, Value: 30}},
+		Conflicts: [][]string{{"tent", "map"}},
+	}
+	tests := []struct {
+		dedupe string
+		want   []Item
+	}{
+		{dedupeNone, []Item{first.Items[0], first.Items[1], second.Items[0], second.Items[1]}},
+		{dedupeDrop, []Item{first.Items[0], first.Items[1], second.Items[1]}},
+		{dedupeSum, []Item{first.Items[0], {Name: "map", Weight: 0.25, Value: 4, Quantity: 3}, second.Items[1]}},
+	}
+	for _, tt := range tests {
+		merged, warnings, err := mergeInstances([]*Instance{first, second}, tt.dedupe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(merged.Items, tt.want) {
+			t.Errorf("%s: items %+v, want %+v", tt.dedupe, merged.Items, tt.want)
+		}
+		// Capacity of the first file is kept, the other one reported
+		if merged.Capacity != 5 || len(warnings) != 1 || len(merged.Conflicts) != 1 {
+			t.Errorf("%s: capacity %v, conflicts %v, warnings %v", tt.dedupe, merged.Capacity, merged.Conflicts, warnings)
+		}
+	}
+	// Summing doesn't change the items of the inputs
+	if first.Items[1].Quantity != 2 {
+		t.Errorf("input quantity changed to %d", first.Items[1].Quantity)
+	}
+	if _, _, err := mergeInstances([]*Instance{first}, "keep"); err == nil {
+		t.Error("unknown dedupe mode accepted")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Wrapper adding behavior around any solver without changing it
+type Middleware func(Solver) Solver
+
+// Wrapping solver into middlewares, the first one is the outermost
+func Chain(solver Solver, middlewares ...Middleware) Solver {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		solver = middlewares[i](solver)
+	}
+	return solver
+}
+
+// Measuring wall time of solver runs into result Duration
+func WrapWithTiming(solver Solver) Solver {
+	return func(ctx context.Context, items []Item, params Params) Result {
+		start := time.Now()
+		result := solver(ctx, items, params)
+		result.Duration = time.Since(start)
+		return result
+	}
+}
+
+// Logging start of every run at trace level and its outcome at debug level
+func WrapWithLogging(logger *slog.Logger) Middleware {
+	return func(solver Solver) Solver {
+		return func(ctx context.Context, items []Item, params Params) Result {
+			logger.Log(ctx, levelTrace, "Run started", "items", len(items), "capacity", params.MaxWeight, "seed", params.Seed)
+			result := solver(ctx, items, params)
+			logger.Debug("Run finished", "seed", result.Seed, "value", result.Value, "weight", result.Weight,
+				"violation", result.Violation, "iterations", result.Iterations, "duration", result.Duration)
+			return result
+		}
+	}
+}
+
+// Checking params before the run and the solution after it, reporting every problem found.
+// Invalid params are reported without running the solver.
+func WrapWithValidation(report func(error)) Middleware {
+	return func(solver Solver) Solver {
+		return func(ctx context.Context, items []Item, params Params) Result {
+			if err := validateParams(items, params); err != nil {
+				report(err)
+				return Result{Solution: make([]int, len(items)), Seed: params.Seed}
+			}
+			result := solver(ctx, items, params)
+			if err := validateResult(items, params, result); err != nil {
+				report(err)
+			}
+			return result
+		}
+	}
+}
+
+// Rerunning solver with the next seeds while it finds no solution satisfying all
+// constraints, at most attempts times in total, and taking the best result
+func WrapWithRetry(attempts int) Middleware {
+	return func(solver Solver) Solver {
+		return func(ctx context.Context, items []Item, params Params) Result {
+			if params.Seed == 0 {
+				params.Seed = time.Now().UnixNano()
+			}
+			var results []Result
+			for i := 0; i < attempts || i == 0; i++ {
+				config := params
+				config.Seed = params.Seed + int64(i)
+				// Caller-provided source can't be restarted
+				if i > 0 {
+					config.Source = nil
+				}
+				result := solver(ctx, items, config)
+				results = append(results, result)
+				if result.Violation == 0 || ctx.Err() != nil {
+					break
+				}
+			}
+			return bestResult(results, params.Canonical)
+		}
+	}
+}
+
+// Checking params solver can work with
+func validateParams(items []Item, params Params) error {
+	if params.MaxWeight <= 0 || math.IsNaN(params.MaxWeight) {
+		return fmt.Errorf("capacity must be positive, got %g", params.MaxWeight)
+	}
+	for _, i := range params.Locked {
+		if i < 0 || i >= len(items) {
+			return fmt.Errorf("locked item %d out of range of %d items", i, len(items))
+		}
+	}
+	if params.Initial != nil && len(params.Initial) != len(items) {
+		return fmt.Errorf("initial solution has %d entries for %d items", len(params.Initial), len(items))
+	}
+	return nil
+}
+
+// Checking that result solution is feasible and its totals are right
+func validateResult(items []Item, params Params, result Result) error {
+	if len(result.Solution) != len(items) {
+		return fmt.Errorf("solution has %d entries for %d items", len(result.Solution), len(items))
+	}
+	// Evaluating the way the solver does, with curves and compensated summation
+	params.CacheSize = 0
+	value, weight := newEvaluator(items, params).evaluate(result.Solution)
+	if weight > params.MaxWeight {
+		return fmt.Errorf("solution weight %g exceeds capacity %g", weight, params.MaxWeight)
+	}
+	if value != result.Value {
+		return fmt.Errorf("solution value is %d, result reports %d", value, result.Value)
+	}
+	for _, i := range params.Locked {
+		if result.Solution[i] != 1 {
+			return fmt.Errorf("locked item %d (%s) is not in the solution", i, items[i].Name)
+		}
+	}
+	if !satisfiesHard(params.Constraints, result.Solution, items) {
+		return fmt.Errorf("hard constraints are not satisfied")
+	}
+	return nil
+}
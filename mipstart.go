@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Name of MIP variable of i-th item: x_i, or sanitized item name,
+// so it matches the variables of the user's model
+func mipVarName(i int, item Item, byName bool) string {
+	if !byName || item.Name == "" {
+		return fmt.Sprintf("x_%d", i)
+	}
+	// LP format names can't have spaces and operators, nor start with a digit
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, item.Name)
+	if unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// Writing solution as Gurobi MIP start (MST) file
+func writeMST(w io.Writer, entry PoolEntry, items []Item, byName bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# MIP start, objective value %d\n", entry.Value)
+	for i, included := range entry.Solution {
+		fmt.Fprintf(bw, "%s %d\n", mipVarName(i, items[i], byName), included)
+	}
+	return bw.Flush()
+}
+
+// Writing solution as CBC solution file, readable by its -mipstart option
+func writeCBCSolution(w io.Writer, entry PoolEntry, items []Item, byName bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "Feasible - objective value %d\n", entry.Value)
+	for i, included := range entry.Solution {
+		fmt.Fprintf(bw, "%7d %s %d %d\n", i, mipVarName(i, items[i], byName), included, items[i].Value)
+	}
+	return bw.Flush()
+}
+
+// Exporting pool solutions to files prefix1.mst, prefix2.mst and so on, best first
+func exportPool(pool *solutionPool, items []Item, prefix, format string, byName bool) ([]string, error) {
+	write := writeMST
+	extension := ".mst"
+	switch format {
+	case "mst":
+	case "cbc":
+		write = writeCBCSolution
+		extension = ".sol"
+	default:
+		return nil, fmt.Errorf("unknown pool format %q, expected mst or cbc", format)
+	}
+
+	var files []string
+	for rank, entry := range pool.entries {
+		filename := fmt.Sprintf("%s%d%s", prefix, rank+1, extension)
+		file, err := os.Create(filename)
+		if err != nil {
+			return files, err
+		}
+		err = write(file, entry, items, byName)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Knapsack of items left out of all knapsacks
+const unassigned = -1
+
+// Multiple knapsack problem: every item goes into at most one of the knapsacks.
+// Items with weights and values by knapsack make it the generalized assignment problem.
+type multiProblem struct {
+	items      []Item
+	capacities []float64
+}
+
+// Weight of item i in knapsack k
+func (p *multiProblem) weight(i, k int) float64 {
+	if weights := p.items[i].Weights; k < len(weights) {
+		return weights[k]
+	}
+	return p.items[i].Weight
+}
+
+// Value of item i in knapsack k, nothing when it's in none
+func (p *multiProblem) value(i, k int) int {
+	if k == unassigned {
+		return 0
+	}
+	if values := p.items[i].Values; k < len(values) {
+		return values[k]
+	}
+	return p.items[i].Value
+}
+
+// Checking that items have weights and values of existing knapsacks only
+func (p *multiProblem) check() error {
+	for _, item := range p.items {
+		if len(item.Weights) > len(p.capacities) || len(item.Values) > len(p.capacities) {
+			return fmt.Errorf("item %s has weights or values of more than %d knapsacks", item.Name, len(p.capacities))
+		}
+		for _, weight := range item.Weights {
+			if weight < 0 {
+				return fmt.Errorf("item %s has negative weight %v", item.Name, weight)
+			}
+		}
+	}
+	return nil
+}
+
+// Assignment of items to knapsacks with loads and total value kept up to date
+type multiState struct {
+	p          *multiProblem
+	assignment []int
+	loads      []float64
+	value      int
+}
+
+func newMultiState(p *multiProblem) *multiState {
+	s := &multiState{p: p, assignment: make([]int, len(p.items)), loads: make([]float64, len(p.capacities))}
+	for i := range s.assignment {
+		s.assignment[i] = unassigned
+	}
+	return s
+}
+
+// Moving item i into knapsack k, or out of all of them
+func (s *multiState) move(i, k int) {
+	if from := s.assignment[i]; from != unassigned {
+		s.loads[from] -= s.p.weight(i, from)
+		s.value -= s.p.value(i, from)
+	}
+	if k != unassigned {
+		s.loads[k] += s.p.weight(i, k)
+		s.value += s.p.value(i, k)
+	}
+	s.assignment[i] = k
+}
+
+func (s *multiState) fits(k int) bool {
+	return k == unassigned || s.loads[k] <= s.p.capacities[k]+1e-9
+}
+
+// Putting items in order of value density into the knapsack where they are worth the most,
+// the fullest one on ties
+func (s *multiState) greedy() {
+	for _, i := range densityOrder(s.p.items) {
+		best := unassigned
+		for k := range s.p.capacities {
+			if s.loads[k]+s.p.weight(i, k) > s.p.capacities[k] || s.p.value(i, k) <= 0 {
+				continue
+			}
+			if best == unassigned || s.p.value(i, k) > s.p.value(i, best) ||
+				s.p.value(i, k) == s.p.value(i, best) && s.p.capacities[k]-s.loads[k] < s.p.capacities[best]-s.loads[best] {
+				best = k
+			}
+		}
+		if best != unassigned {
+			s.move(i, best)
+		}
+	}
+}
+
+// Best assignment found by the multiple knapsack solver
+type multiResult struct {
+	// Knapsack of every item, unassigned for items in none
+	Assignment []int
+	Value      int
+	Loads      []float64
+	Iterations int
+	Seed       int64
+	Duration   time.Duration
+}
+
+// Simulated annealing over assignments: candidates move an item into another knapsack
+// or out of all of them, or swap two items of different knapsacks. Stops early with the
+// best assignment so far when context is done.
+func solveMultiple(ctx context.Context, p *multiProblem, params Params) multiResult {
+	start := time.Now()
+	rnd := newRand(params)
+	s := newMultiState(p)
+	s.greedy()
+	best := append([]int(nil), s.assignment...)
+	bestValue := s.value
+
+	epochLength := params.EpochLength
+	if epochLength < 1 {
+		epochLength = 1
+	}
+	iterations := 0
+	for temp := params.MaxTemp; temp > params.MinTemp && len(p.items) > 0; {
+		iterations++
+		current := s.value
+		i := rnd.Intn(len(p.items))
+		from := s.assignment[i]
+		// Undoing the move if it's refused
+		var undo func()
+		if j := rnd.Intn(len(p.items)); rnd.Intn(2) == 0 && s.assignment[j] != from {
+			to := s.assignment[j]
+			s.move(i, to)
+			s.move(j, from)
+			undo = func() { s.move(j, to); s.move(i, from) }
+			if !s.fits(from) || !s.fits(to) {
+				undo()
+				undo = nil
+			}
+		} else {
+			to := rnd.Intn(len(p.capacities)+1) - 1
+			if to != from {
+				s.move(i, to)
+				undo = func() { s.move(i, from) }
+				if !s.fits(to) {
+					undo()
+					undo = nil
+				}
+			}
+		}
+		if undo != nil {
+			if candidateIsBetter(current, s.value, temp) > rnd.Float64() {
+				if s.value > bestValue {
+					best = append(best[:0], s.assignment...)
+					bestValue = s.value
+				}
+			} else {
+				undo()
+			}
+		}
+
+		if iterations%epochLength == 0 {
+			temp *= params.CoolingRate
+		}
+		if iterations > 1000000 {
+			slog.Warn("Too many iterations, stopping early")
+			break
+		}
+		if iterations%1024 == 0 && ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Loads are summed again, so they don't carry rounding errors of all the moves
+	result := multiResult{Assignment: best, Value: bestValue, Loads: make([]float64, len(p.capacities)),
+		Iterations: iterations, Seed: params.Seed, Duration: time.Since(start)}
+	for i, k := range best {
+		if k != unassigned {
+			result.Loads[k] += p.weight(i, k)
+		}
+	}
+	return result
+}
+
+// Print items of every knapsack with their totals, and the items left out
+func showMultiple(w io.Writer, p *multiProblem, result multiResult) {
+	byKnapsack := make([][]int, len(p.capacities))
+	var left []string
+	for i, k := range result.Assignment {
+		if k == unassigned {
+			left = append(left, p.items[i].Name)
+		} else {
+			byKnapsack[k] = append(byKnapsack[k], i)
+		}
+	}
+	for k, items := range byKnapsack {
+		value := 0
+		for _, i := range items {
+			value += p.value(i, k)
+		}
+		used := 0.0
+		if p.capacities[k] > 0 {
+			used = 100 * result.Loads[k] / p.capacities[k]
+		}
+		fmt.Fprintf(w, "Knapsack %d: value %d, weight %s of %s (%.1f%% used)\n", k+1, value,
+			formatFloat(result.Loads[k]), formatFloat(p.capacities[k]), used)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, i := range items {
+			fmt.Fprintf(tw, " %s\t%s\t%d\n", p.items[i].Name, formatFloat(p.weight(i, k)), p.value(i, k))
+		}
+		tw.Flush()
+	}
+	sort.Strings(left)
+	fmt.Fprintf(w, "Left out (%d items): %s\n", len(left), strings.Join(left, ", "))
+	fmt.Fprintf(w, "Total value: %d\n", result.Value)
+}
+
+// Parsing comma separated capacities of knapsacks
+func parseCapacities(list string) ([]float64, error) {
+	var capacities []float64
+	for _, field := range splitNames(list) {
+		capacity, err := strconv.ParseFloat(field, 64)
+		if err != nil || capacity <= 0 {
+			return nil, fmt.Errorf("capacity must be a positive number, got %q", field)
+		}
+		capacities = append(capacities, capacity)
+	}
+	if len(capacities) == 0 {
+		return nil, fmt.Errorf("no capacities given")
+	}
+	return capacities, nil
+}
+
+// Multiple subcommand: loading items into several knapsacks at once, like trucks or containers,
+// where items may weigh and be worth differently in every knapsack
+func runMultiple(args []string) {
+	fs := flag.NewFlagSet("multiple", flag.ExitOnError)
+	input := addInputFlags(fs)
+	capacityList := fs.String("capacities", "", "comma separated capacities of the knapsacks, like 5,5,3.5")
+	maxTemp := fs.Float64("max-temp", 1000.0, "initial temperature")
+	minTemp := fs.Float64("min-temp", 0.1, "temperature to stop at")
+	coolingRate := fs.Float64("cooling-rate", 0.95, "temperature multiplier applied after every epoch")
+	epochLength := fs.Int("epoch-length", 100, "iterations per temperature before cooling down")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	timeLimit := fs.Duration("time-limit", 0, "time budget of the solve, unlimited if zero")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	capacities, err := parseCapacities(*capacityList)
+	if err != nil {
+		invalid("Error in -capacities", "err", err)
+	}
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+
+	// Refusing to solve nonsense input like solve does, items over all capacities are just left out
+	problems := validateInstance(instance, slices.Max(capacities))
+	for _, p := range problems {
+		p.log()
+	}
+	if hasErrors(problems) {
+		invalid("Invalid input", "problems", len(problems))
+	}
+	unsupported, err := extraConstraints(instance)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	for _, item := range instance.Items {
+		if item.Required || item.Excluded {
+			unsupported = append(unsupported, "required and excluded items")
+			break
+		}
+	}
+	if len(unsupported) > 0 {
+		invalid("Multiple knapsack problem can't take constraints, curves or synergies", "constraints", strings.Join(unsupported, ","))
+	}
+	_, items := expandQuantities(instance.Items)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	params := Params{MaxTemp: *maxTemp, MinTemp: *minTemp, CoolingRate: *coolingRate, EpochLength: *epochLength, Seed: *seed}
+	ctx := context.Background()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+	p := &multiProblem{items: items, capacities: capacities}
+	if err := p.check(); err != nil {
+		invalid("Error in items", "err", err)
+	}
+	result := solveMultiple(ctx, p, params)
+
+	fmt.Printf("Seed: %d\n", result.Seed)
+	showMultiple(os.Stdout, p, result)
+	fmt.Printf("Execution time: %v\n", result.Duration)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// Every item goes into at most one knapsack, loads fit and add up to the assigned items
+func TestSolveMultiple(t *testing.T) {
+	items := []Item{
+		{Name: "a", Weight: 3, Value: 30},
+		{Name: "b", Weight: 3, Value: 28},
+		{Name: "c", Weight: 2, Value: 15},
+		{Name: "d", Weight: 2, Value: 14},
+		{Name: "e", Weight: 4, Value: 10},
+	}
+	p := &multiProblem{items: items, capacities: []float64{5, 5}}
+	if err := p.check(); err != nil {
+		t.Fatal(err)
+	}
+	params := Params{MaxTemp: 100, MinTemp: 0.1, CoolingRate: 0.95, EpochLength: 50, Seed: 1}
+	result := solveMultiple(context.Background(), p, params)
+
+	loads := make([]float64, len(p.capacities))
+	value := 0
+	for i, k := range result.Assignment {
+		if k != unassigned {
+			loads[k] += items[i].Weight
+			value += items[i].Value
+		}
+	}
+	for k, load := range loads {
+		if load > p.capacities[k] || math.Abs(load-result.Loads[k]) > 1e-9 {
+			t.Errorf("knapsack %d holds %v of %v, result %v", k, load, p.capacities[k], result.Loads[k])
+		}
+	}
+	// a with c and b with d
+	if value != result.Value || value != 87 {
+		t.Errorf("assignment %v is worth %d, result %d, want 87", result.Assignment, value, result.Value)
+	}
+}
+
+func TestParseCapacities(t *testing.T) {
+	capacities, err := parseCapacities("5, 3.5")
+	if err != nil || len(capacities) != 2 || capacities[1] != 3.5 {
+		t.Errorf("capacities %v, error %v", capacities, err)
+	}
+	for _, list := range []string{"", "5,0", "5,x", "-1"} {
+		if _, err := parseCapacities(list); err == nil {
+			t.Errorf("%q accepted", list)
+		}
+	}
+}
+
+// Items weigh and are worth differently by knapsack, like in the generalized assignment problem
+func TestSolveAssignment(t *testing.T) {
+	items := []Item{
+		{Name: "a", Weight: 3, Value: 1, Weights: []float64{3, 1}, Values: []int{5, 20}},
+		{Name: "b", Weight: 3, Value: 1, Weights: []float64{1, 3}, Values: []int{20, 5}},
+		{Name: "c", Weight: 2, Value: 8},
+	}
+	p := &multiProblem{items: items, capacities: []float64{3, 3}}
+	if err := p.check(); err != nil {
+		t.Fatal(err)
+	}
+	if p.weight(0, 1) != 1 || p.value(0, 1) != 20 || p.weight(2, 1) != 2 || p.value(2, unassigned) != 0 {
+		t.Errorf("weights and values by knapsack are not used")
+	}
+	params := Params{MaxTemp: 100, MinTemp: 0.1, CoolingRate: 0.95, EpochLength: 50, Seed: 1}
+	result := solveMultiple(context.Background(), p, params)
+	// a goes into the second knapsack and b into the first, where they are worth the most, c into either
+	if result.Assignment[0] != 1 || result.Assignment[1] != 0 || result.Value != 48 {
+		t.Errorf("assignment %v worth %d, want a in 2, b in 1 worth 48", result.Assignment, result.Value)
+	}
+
+	p.capacities = p.capacities[:1]
+	if err := p.check(); err == nil {
+		t.Error("weights of more knapsacks than there are accepted")
+	}
+}
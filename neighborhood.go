@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Operator generating candidate solution close to the current one
+type Neighborhood interface {
+	Candidate(solution []int, rnd *rand.Rand) []int
+}
+
+// Inverting one random item
+type flipNeighborhood struct{}
+
+func (flipNeighborhood) Candidate(solution []int, rnd *rand.Rand) []int {
+	return generateCandidate(solution, rnd)
+}
+
+// Removing one included item and adding one excluded item in a single move
+type swapNeighborhood struct{}
+
+func (swapNeighborhood) Candidate(solution []int, rnd *rand.Rand) []int {
+	// Splitting item indexes into included and excluded ones
+	var included, excluded []int
+	for i, inc := range solution {
+		if inc == 1 {
+			included = append(included, i)
+		} else {
+			excluded = append(excluded, i)
+		}
+	}
+
+	// Swap is impossible for empty or full knapsack, flipping one item instead
+	if len(included) == 0 || len(excluded) == 0 {
+		return generateCandidate(solution, rnd)
+	}
+
+	candidate := make([]int, len(solution))
+	copy(candidate, solution)
+	candidate[included[rnd.Intn(len(included))]] = 0
+	candidate[excluded[rnd.Intn(len(excluded))]] = 1
+	return candidate
+}
+
+// Inverting K distinct random items at once.
+// If RandomK is set, move size is drawn uniformly from 1 to K for every move.
+type kFlipNeighborhood struct {
+	K       int
+	RandomK bool
+}
+
+func (n kFlipNeighborhood) Candidate(solution []int, rnd *rand.Rand) []int {
+	k := n.K
+	if n.RandomK {
+		k = 1 + rnd.Intn(n.K)
+	}
+	if k > len(solution) {
+		k = len(solution)
+	}
+
+	candidate := make([]int, len(solution))
+	copy(candidate, solution)
+	// Taking first k indexes of random permutation, so no item is flipped twice
+	for _, index := range rnd.Perm(len(solution))[:k] {
+		candidate[index] = 1 - candidate[index]
+	}
+	return candidate
+}
+
+// Choosing one of the operators for every move, with probability proportional to its weight.
+// Operators are chosen uniformly if Weights is empty.
+type mixedNeighborhood struct {
+	Operators []Neighborhood
+	Weights   []float64
+}
+
+func (n mixedNeighborhood) Candidate(solution []int, rnd *rand.Rand) []int {
+	if len(n.Weights) == 0 {
+		return n.Operators[rnd.Intn(len(n.Operators))].Candidate(solution, rnd)
+	}
+
+	// Roulette wheel selection of operator
+	total := 0.0
+	for _, w := range n.Weights {
+		total += w
+	}
+	r := rnd.Float64() * total
+	for i, w := range n.Weights {
+		r -= w
+		if r < 0 {
+			return n.Operators[i].Candidate(solution, rnd)
+		}
+	}
+	return n.Operators[len(n.Operators)-1].Candidate(solution, rnd)
+}
+
+// Greedy biased moves mixed with random flips.
+// With probability Rate the move either adds the densest excluded item that still fits,
+// or removes the least dense included item. Otherwise one random item is inverted.
+type guidedNeighborhood struct {
+	Rate      float64
+	items     []Item
+	maxWeight float64
+	// Item indexes sorted by value density, the densest first
+	order []int
+}
+
+// Creating guided neighborhood for given items and knapsack capacity
+func newGuidedNeighborhood(items []Item, maxWeight, rate float64) *guidedNeighborhood {
+	return &guidedNeighborhood{Rate: rate, items: items, maxWeight: maxWeight, order: densityOrder(items)}
+}
+
+func (n *guidedNeighborhood) Candidate(solution []int, rnd *rand.Rand) []int {
+	if rnd.Float64() >= n.Rate {
+		return generateCandidate(solution, rnd)
+	}
+
+	candidate := make([]int, len(solution))
+	copy(candidate, solution)
+
+	if rnd.Intn(2) == 0 {
+		// Adding the densest excluded item which fits into remaining capacity
+		_, weight := computeEnergy(solution, n.items)
+		for _, i := range n.order {
+			if candidate[i] == 0 && weight+n.items[i].Weight <= n.maxWeight {
+				candidate[i] = 1
+				return candidate
+			}
+		}
+	} else {
+		// Removing the least dense included item
+		for j := len(n.order) - 1; j >= 0; j-- {
+			if i := n.order[j]; candidate[i] == 1 {
+				candidate[i] = 0
+				return candidate
+			}
+		}
+	}
+
+	// Greedy move is not possible, falling back to random flip
+	return generateCandidate(solution, rnd)
+}
+
+// Creating neighborhood by name, k is the max move size of k-flip operator, or its only one if fixedK.
+// Items and capacity are used by guided operator.
+func newNeighborhood(name string, k int, fixedK bool, items []Item, maxWeight float64) (Neighborhood, error) {
+	switch name {
+	case "flip":
+		return flipNeighborhood{}, nil
+	case "swap":
+		return swapNeighborhood{}, nil
+	case "kflip":
+		if k < 1 {
+			return nil, fmt.Errorf("k-flip move size must be positive, got %d", k)
+		}
+		return kFlipNeighborhood{K: k, RandomK: !fixedK}, nil
+	case "mixed":
+		return mixedNeighborhood{Operators: []Neighborhood{flipNeighborhood{}, swapNeighborhood{}}}, nil
+	case "guided":
+		return newGuidedNeighborhood(items, maxWeight, 0.5), nil
+	}
+	return nil, fmt.Errorf("unknown neighborhood %q", name)
+}
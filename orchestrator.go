@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Outcome of a solver run
+type Result struct {
+	Solution []int
+	Value    int
+	Weight   float64
+	// Soft constraint violation of the solution, 0 when all are satisfied
+	Violation float64
+	// Best distinct solutions found, nil unless params asked for a pool
+	Pool       *solutionPool
+	Iterations int
+	// Number of accepted moves
+	Accepted int
+	// Iteration the best solution was found at, 0 for the initial solution
+	BestIteration int
+	// Seed the run was started with
+	Seed     int64
+	Duration time.Duration
+	// Algorithm that found the solution, for manifests
+	Algorithm string
+	// Copies taken of every item with quantity, nil unless items were expanded into copies
+	Counts []int
+}
+
+// Solver finding solution for items, returning the best one found so far when context is done
+type Solver func(ctx context.Context, items []Item, params Params) Result
+
+// Running composite solves (portfolios, restarts, fallback chains) under one context tree.
+// The whole solve shares one time budget, and cancelling the parent context stops every run.
+type Orchestrator struct {
+	// Time budget of the whole solve, unlimited if zero
+	Budget time.Duration
+	// Max number of concurrent runs, number of CPUs if zero
+	Workers int
+	// Breaking ties between runs by canonical solution order instead of run order
+	Canonical bool
+	// Called as soon as every run finishes, concurrently for concurrent runs
+	OnResult func(params Params, result Result)
+}
+
+// Running solver and reporting its result
+func (o *Orchestrator) run(ctx context.Context, items []Item, params Params, solver Solver) Result {
+	result := solver(ctx, items, params)
+	if o.OnResult != nil {
+		o.OnResult(params, result)
+	}
+	return result
+}
+
+// Deriving context limited by the time budget
+func (o *Orchestrator) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.Budget > 0 {
+		return context.WithTimeout(ctx, o.Budget)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Running solver for every config concurrently, returning all results in config order
+func (o *Orchestrator) RunAll(ctx context.Context, items []Item, configs []Params, solver Solver) []Result {
+	ctx, cancel := o.context(ctx)
+	defer cancel()
+	return o.runAll(ctx, items, configs, solver)
+}
+
+// Max number of concurrent runs
+func (o *Orchestrator) workers() int {
+	if o.Workers < 1 {
+		return runtime.NumCPU()
+	}
+	return o.Workers
+}
+
+// Running solver for every config concurrently within context
+func (o *Orchestrator) runAll(ctx context.Context, items []Item, configs []Params, solver Solver) []Result {
+	slots := make(chan struct{}, o.workers())
+
+	results := make([]Result, len(configs))
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		wg.Add(1)
+		go func(i int, config Params) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = o.run(ctx, items, config, solver)
+		}(i, config)
+	}
+	wg.Wait()
+	return results
+}
+
+// Running differently configured solvers concurrently and taking the best result
+func (o *Orchestrator) Portfolio(ctx context.Context, items []Item, configs []Params, solver Solver) Result {
+	return bestResult(o.RunAll(ctx, items, configs, solver), o.Canonical)
+}
+
+// Running solver n times with consecutive seeds and taking the best result.
+// Runs get their own random generators, so caller-provided Source is used only for a single run.
+func (o *Orchestrator) Restarts(ctx context.Context, items []Item, params Params, n int, solver Solver) Result {
+	if n <= 1 {
+		ctx, cancel := o.context(ctx)
+		defer cancel()
+		return o.run(ctx, items, params, solver)
+	}
+	return o.Portfolio(ctx, items, restartConfigs(params, n), solver)
+}
+
+// Params of n restarts, seeded with consecutive seeds starting from params seed
+func restartConfigs(params Params, n int) []Params {
+	if params.Seed == 0 {
+		params.Seed = time.Now().UnixNano()
+	}
+	configs := make([]Params, n)
+	for i := range configs {
+		configs[i] = params
+		configs[i].Seed = params.Seed + int64(i)
+		configs[i].Source = nil
+	}
+	return configs
+}
+
+// Solver of a fallback chain with its own time limit, unlimited if zero
+type Stage struct {
+	Solver Solver
+	Limit  time.Duration
+}
+
+// Trying solvers one after another until one finishes within its time limit or the budget
+// runs out. Stopped stages still give their best solution so far, the best of all is taken.
+func (o *Orchestrator) Fallback(ctx context.Context, items []Item, params Params, stages ...Stage) Result {
+	ctx, cancel := o.context(ctx)
+	defer cancel()
+
+	var results []Result
+	for _, stage := range stages {
+		stageCtx, stageCancel := ctx, context.CancelFunc(func() {})
+		if stage.Limit > 0 {
+			stageCtx, stageCancel = context.WithTimeout(ctx, stage.Limit)
+		}
+		results = append(results, o.run(stageCtx, items, params, stage.Solver))
+		stopped := stageCtx.Err() != nil
+		stageCancel()
+		if !stopped || ctx.Err() != nil {
+			break
+		}
+	}
+	return bestResult(results, o.Canonical)
+}
+
+// Choosing result with the least violation and the highest value, the earliest one
+// or the canonical one on ties
+func bestResult(results []Result, canonical bool) Result {
+	var best Result
+	var pool *solutionPool
+	for i, result := range results {
+		tie := result.Violation == best.Violation && result.Value == best.Value
+		if i == 0 || result.Violation < best.Violation ||
+			result.Violation == best.Violation && result.Value > best.Value ||
+			canonical && tie && lexLess(result.Solution, best.Solution) {
+			best = result
+		}
+		// Pools of all runs are merged, not just the best one
+		if result.Pool != nil {
+			if pool == nil {
+				pool = newSolutionPool(result.Pool.size, result.Pool.minDistance)
+			}
+			pool.merge(result.Pool)
+		}
+	}
+	best.Pool = pool
+	return best
+}
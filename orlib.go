@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// OR-Library / Pisinger plain-text instances: number of items n, then n "value weight" pairs,
+// then capacity. Pairs prefixed with item index ("i value weight") are accepted as well.
+
+// Reading instance in OR-Library format
+func readORLib(r io.Reader) (*Instance, error) {
+	// Format is whitespace separated, line breaks carry no meaning
+	var tokens []string
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty instance")
+	}
+
+	n, err := strconv.Atoi(tokens[0])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid number of items %q", tokens[0])
+	}
+
+	// Telling pairs from indexed triples by the number of tokens
+	var fields int
+	switch len(tokens) {
+	case 2*n + 2:
+		fields = 2
+	case 3*n + 2:
+		fields = 3
+	default:
+		return nil, fmt.Errorf("expected %d items followed by capacity, got %d values", n, len(tokens)-1)
+	}
+
+	items := make([]Item, n)
+	for i := range items {
+		record := tokens[1+i*fields : 1+(i+1)*fields]
+		record = record[fields-2:]
+		value, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("item %d: invalid value %q", i+1, record[0])
+		}
+		weight, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: invalid weight %q", i+1, record[1])
+		}
+		items[i] = Item{Name: "item" + strconv.Itoa(i+1), Weight: weight, Value: value}
+	}
+
+	capacity, err := strconv.ParseFloat(tokens[len(tokens)-1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capacity %q", tokens[len(tokens)-1])
+	}
+	return &Instance{Items: items, Capacity: capacity}, nil
+}
+
+// Writing instance in OR-Library format, item names are not preserved
+func writeORLib(w io.Writer, instance *Instance) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d\n", len(instance.Items))
+	for _, item := range instance.Items {
+		fmt.Fprintf(bw, "%d %s\n", item.Value, strconv.FormatFloat(item.Weight, 'g', -1, 64))
+	}
+	fmt.Fprintf(bw, "%s\n", strconv.FormatFloat(instance.Capacity, 'g', -1, 64))
+	return bw.Flush()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadORLib(t *testing.T) {
+	want := &Instance{Capacity: 10, Items: []Item{
+		{Name: "item1", Weight: 5, Value: 30}, {Name: "item2", Weight: 4.5, Value: 24}, {Name: "item3", Weight: 1, Value: 3},
+	}}
+	// Line breaks carry no meaning, indexed triples are the same items
+	for _, input := range []string{"3\n30 5\n24 4.5\n3 1\n10\n", "3 1 30 5 2 24 4.5\n3 3 1 10"} {
+		instance, err := readORLib(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(instance, want) {
+			t.Errorf("%q: instance %+v, want %+v", input, instance, want)
+		}
+	}
+
+	for _, input := range []string{"", "two\n", "3\n30 5\n24 4.5\n10\n", "2\n30 5\n24 heavy\n10\n", "1\n30 5\nfull\n"} {
+		if _, err := readORLib(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: no error", input)
+		}
+	}
+}
+
+// Written instance reads back with the same weights, values and capacity
+func TestWriteORLib(t *testing.T) {
+	instance := &Instance{Capacity: 7.5, Items: []Item{{Name: "item1", Weight: 0.25, Value: 4}, {Name: "item2", Weight: 3, Value: 12}}}
+	var buf bytes.Buffer
+	if err := writeORLib(&buf, instance); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "2\n4 0.25\n12 3\n7.5\n" {
+		t.Errorf("output %q", buf.String())
+	}
+	read, err := readORLib(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, instance) {
+		t.Errorf("read back %+v, want %+v", read, instance)
+	}
+}
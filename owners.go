@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Totals of selected items of one owner
+type ownerTotals struct {
+	Owner  string
+	Count  int
+	Value  int
+	Weight float64
+}
+
+// Valuing solutions with category curves and synergies, the way the evaluator does.
+// Evaluators are built once and reused, every concurrent run takes its own set from the pool.
+type ownerValuation struct {
+	Curves    map[string]Curve
+	Synergies []Synergy
+	states    *sync.Pool
+}
+
+func newOwnerValuation(curves map[string]Curve, synergies []Synergy) ownerValuation {
+	return ownerValuation{Curves: curves, Synergies: synergies, states: &sync.Pool{}}
+}
+
+func (v ownerValuation) linear() bool {
+	return len(v.Curves) == 0 && len(v.Synergies) == 0
+}
+
+func (v ownerValuation) value(solution []int, items []Item) int {
+	if v.linear() {
+		value, _ := computeEnergy(solution, items)
+		return value
+	}
+	value := 0
+	v.with(items, func(s *ownerValues) {
+		value, _ = s.total.evaluate(solution)
+	})
+	return value
+}
+
+// Running f with evaluators of items, built anew when there are none for them in the pool
+func (v ownerValuation) with(items []Item, f func(s *ownerValues)) {
+	var s *ownerValues
+	if v.states != nil {
+		s, _ = v.states.Get().(*ownerValues)
+	}
+	if s == nil || !s.valuing(items) {
+		s = newOwnerValues(items, v)
+	}
+	f(s)
+	if v.states != nil {
+		v.states.Put(s)
+	}
+}
+
+// Evaluators of the whole solution and of the items of every owner on their own
+type ownerValues struct {
+	items      []Item
+	total      *evaluator
+	owners     []string
+	ownerOf    []int // Index of owner of every item, -1 for items without owner
+	evaluators []*evaluator
+	owned      [][]int // Selection of items of every owner, in order of their items
+}
+
+func newOwnerValues(items []Item, v ownerValuation) *ownerValues {
+	params := Params{Curves: v.Curves, Synergies: v.Synergies}
+	s := &ownerValues{items: items, total: newEvaluator(items, params), ownerOf: make([]int, len(items))}
+	index := map[string]int{}
+	var ownedItems [][]Item
+	for i, item := range items {
+		s.ownerOf[i] = -1
+		if item.Owner == "" {
+			continue
+		}
+		o, ok := index[item.Owner]
+		if !ok {
+			o = len(s.owners)
+			index[item.Owner] = o
+			s.owners = append(s.owners, item.Owner)
+			ownedItems = append(ownedItems, nil)
+		}
+		s.ownerOf[i] = o
+		ownedItems[o] = append(ownedItems[o], item)
+	}
+	for _, owned := range ownedItems {
+		s.evaluators = append(s.evaluators, newEvaluator(owned, params))
+		s.owned = append(s.owned, make([]int, len(owned)))
+	}
+	return s
+}
+
+func (s *ownerValues) valuing(items []Item) bool {
+	return len(s.items) == len(items) && (len(items) == 0 || &s.items[0] == &items[0])
+}
+
+// Values of items of every owner on their own, in order of owners
+func (s *ownerValues) values(solution []int) []int {
+	next := make([]int, len(s.owners))
+	for i, o := range s.ownerOf {
+		if o >= 0 {
+			s.owned[o][next[o]] = solution[i]
+			next[o]++
+		}
+	}
+	values := make([]int, len(s.owners))
+	for o, e := range s.evaluators {
+		values[o], _ = e.evaluate(s.owned[o])
+	}
+	return values
+}
+
+// Summing selected items by owner, sorted by owner name. Value of owner is the value of
+// their selected items on their own, with curves and synergies among them.
+// Owners without selected items are listed too, with zero totals.
+func ownerSummary(solution []int, items []Item, valuation ownerValuation) []ownerTotals {
+	byOwner := map[string]*ownerTotals{}
+	for i, item := range items {
+		if item.Owner == "" {
+			continue
+		}
+		totals, ok := byOwner[item.Owner]
+		if !ok {
+			totals = &ownerTotals{Owner: item.Owner}
+			byOwner[item.Owner] = totals
+		}
+		if solution[i] == 1 {
+			totals.Count++
+			totals.Value += item.Value
+			totals.Weight += item.Weight
+		}
+	}
+	if !valuation.linear() {
+		valuation.with(items, func(s *ownerValues) {
+			for o, value := range s.values(solution) {
+				byOwner[s.owners[o]].Value = value
+			}
+		})
+	}
+
+	summary := make([]ownerTotals, 0, len(byOwner))
+	for _, totals := range byOwner {
+		summary = append(summary, *totals)
+	}
+	sort.Slice(summary, func(a, b int) bool {
+		return summary[a].Owner < summary[b].Owner
+	})
+	return summary
+}
+
+// Cap on share of total selected value going to a single owner
+type ownerShareLimit struct {
+	MaxShare float64
+	ownerValuation
+}
+
+func (c ownerShareLimit) Name() string {
+	return fmt.Sprintf("owner share <= %g", c.MaxShare)
+}
+
+func (c ownerShareLimit) Satisfied(solution []int, items []Item) bool {
+	total := c.value(solution, items)
+	if total <= 0 {
+		return true
+	}
+	for _, totals := range ownerSummary(solution, items, c.ownerValuation) {
+		if float64(totals.Value) > c.MaxShare*float64(total) {
+			return false
+		}
+	}
+	return true
+}
+
+// Value above the cap of all owners, relative to total value
+func (c ownerShareLimit) Violation(solution []int, items []Item) float64 {
+	total := c.value(solution, items)
+	if total <= 0 {
+		return 0
+	}
+	excess := 0.0
+	for _, totals := range ownerSummary(solution, items, c.ownerValuation) {
+		excess += math.Max(0, float64(totals.Value)-c.MaxShare*float64(total))
+	}
+	return excess / float64(total)
+}
+
+// Guaranteed value for every owner, the max-min fairness condition
+type ownerMinValue struct {
+	MinValue int
+	ownerValuation
+}
+
+func (c ownerMinValue) Name() string {
+	return fmt.Sprintf("owner value >= %d", c.MinValue)
+}
+
+func (c ownerMinValue) Satisfied(solution []int, items []Item) bool {
+	for _, totals := range ownerSummary(solution, items, c.ownerValuation) {
+		if totals.Value < c.MinValue {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing value of all owners, relative to the guaranteed value
+func (c ownerMinValue) Violation(solution []int, items []Item) float64 {
+	if c.MinValue <= 0 {
+		return 0
+	}
+	missing := 0
+	for _, totals := range ownerSummary(solution, items, c.ownerValuation) {
+		if totals.Value < c.MinValue {
+			missing += c.MinValue - totals.Value
+		}
+	}
+	return float64(missing) / float64(c.MinValue)
+}
+
+// Print totals of the knapsack by owner, valued like the constraints value them
+func showOwners(w io.Writer, solution []int, items []Item, valuation ownerValuation) {
+	summary := ownerSummary(solution, items, valuation)
+	if len(summary) == 0 {
+		return
+	}
+	total := valuation.value(solution, items)
+
+	fmt.Fprintln(w, "Items by owner:")
+	for _, totals := range summary {
+		share := 0.0
+		if total > 0 {
+			share = float64(totals.Value) / float64(total)
+		}
+		fmt.Fprintf(w, " - %s (Items: %d, Weight: %f, Value: %d, Share: %.1f%%)\n",
+			totals.Owner, totals.Count, totals.Weight, totals.Value, share*100)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Owner share counts synergies the objective counts, not just item values
+func TestOwnerShareSynergies(t *testing.T) {
+	items := []Item{
+		{Name: "camera", Weight: 1, Value: 5, Owner: "ann"},
+		{Name: "lens", Weight: 1, Value: 5, Owner: "ann"},
+		{Name: "tent", Weight: 1, Value: 10, Owner: "bob"},
+	}
+	solution := []int{1, 1, 1}
+	linear := ownerShareLimit{MaxShare: 0.6}
+	if !linear.Satisfied(solution, items) {
+		t.Error("linear values give ann half of the value, within the limit")
+	}
+
+	valuation := newOwnerValuation(nil, []Synergy{{Items: []string{"camera", "lens"}, Bonus: 10}})
+	limit := ownerShareLimit{MaxShare: 0.6, ownerValuation: valuation}
+	if limit.Satisfied(solution, items) {
+		t.Error("with the bonus ann gets 20 of 30, over the limit")
+	}
+	if violation, want := limit.Violation(solution, items), (20-0.6*30)/30; math.Abs(violation-want) > 1e-12 {
+		t.Errorf("violation %v, want %v", violation, want)
+	}
+	summary := ownerSummary(solution, items, valuation)
+	if len(summary) != 2 || summary[0].Value != 20 || summary[1].Value != 10 {
+		t.Errorf("summary %+v", summary)
+	}
+	if (ownerMinValue{MinValue: 15}).Satisfied([]int{1, 1}, items[:2]) {
+		t.Error("linear values give ann 10, under the guaranteed 15")
+	}
+	if !(ownerMinValue{MinValue: 15, ownerValuation: valuation}).Satisfied([]int{1, 1}, items[:2]) {
+		t.Error("ann gets 20 with the bonus, over the guaranteed 15")
+	}
+}
+
+// Evaluators reused across solutions value owners like fresh ones
+func TestOwnerValuationReuse(t *testing.T) {
+	items := []Item{
+		{Name: "camera", Weight: 1, Value: 5, Owner: "ann", Category: "gear"},
+		{Name: "tent", Weight: 1, Value: 10, Owner: "bob", Category: "gear"},
+		{Name: "lens", Weight: 1, Value: 4, Owner: "ann", Category: "gear"},
+		{Name: "map", Weight: 1, Value: 2},
+		{Name: "stove", Weight: 1, Value: 7, Owner: "bob"},
+	}
+	curves := map[string]Curve{"gear": {{Count: 1, Factor: 1}, {Count: 2, Factor: 0.5}}}
+	synergies := []Synergy{{Items: []string{"camera", "lens"}, Bonus: 3}, {Items: []string{"tent", "stove"}, Bonus: 6}}
+	reused := newOwnerValuation(curves, synergies)
+	for mask := 0; mask < 1<<len(items); mask++ {
+		solution := make([]int, len(items))
+		for i := range solution {
+			solution[i] = mask >> i & 1
+		}
+		fresh := newOwnerValuation(curves, synergies)
+		if got, want := reused.value(solution, items), fresh.value(solution, items); got != want {
+			t.Errorf("%v: value %d, want %d", solution, got, want)
+		}
+		got, want := ownerSummary(solution, items, reused), ownerSummary(solution, items, fresh)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: summary %+v, want %+v", solution, got, want)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Objective of multi-objective mode: value is maximized, weight, risk, number of items
+// and consumption of resources are minimized
+type objective struct {
+	Name     string
+	Maximize bool
+	of       func(solution []int) float64
+	// Largest amount the objective differs by between solutions, for scaling objectives to each other
+	scale float64
+	// Decimals of the item attribute summed up, objectives are rounded and printed to them
+	decimals int
+}
+
+// Number of decimals of an item attribute, 6 for more than 4 decimal places like weightDecimals
+func attributeDecimals(items []Item, of func(item Item) float64) int {
+	values := make([]Item, len(items))
+	for i, item := range items {
+		values[i].Weight = of(item)
+	}
+	return weightDecimals(values)
+}
+
+// Rounding sum of the objective to the decimals of its items, so that summation noise
+// doesn't make equal solutions differ
+func (o objective) round(x float64) float64 {
+	unit := math.Pow(10, float64(o.decimals))
+	return math.Round(x*unit) / unit
+}
+
+func (o objective) format(x float64) string {
+	return strconv.FormatFloat(x, 'f', o.decimals, 64)
+}
+
+// Parsing comma separated objectives like "value,risk", names besides value, weight, risk
+// and items are taken as resources
+func parseObjectives(list string, items []Item, eval *evaluator) ([]objective, error) {
+	var objectives []objective
+	seen := map[string]bool{}
+	for _, name := range splitNames(list) {
+		if seen[name] {
+			return nil, fmt.Errorf("objective %s is given twice", name)
+		}
+		seen[name] = true
+		o := objective{Name: name}
+		scale := 0.0
+		switch name {
+		case "value":
+			o.Maximize = true
+			o.of = func(solution []int) float64 {
+				value, _ := eval.evaluate(solution)
+				return float64(value)
+			}
+			for _, item := range items {
+				scale += math.Abs(float64(item.Value))
+			}
+			scale += float64(maxSynergyBonus(eval.synergies))
+		case "weight":
+			o.of = func(solution []int) float64 {
+				_, weight := eval.evaluate(solution)
+				return weight
+			}
+			for _, item := range items {
+				scale += item.Weight
+			}
+			o.decimals = weightDecimals(items)
+		case "risk":
+			o.of = func(solution []int) float64 {
+				var risk neumaierSum
+				for i, included := range solution {
+					if included == 1 {
+						risk.add(items[i].Risk)
+					}
+				}
+				return risk.value()
+			}
+			for _, item := range items {
+				scale += item.Risk
+			}
+			o.decimals = attributeDecimals(items, func(item Item) float64 { return item.Risk })
+		case "items":
+			o.of = func(solution []int) float64 {
+				return float64(selectedCount(solution))
+			}
+			scale = float64(len(items))
+		default:
+			if !containsName(resourceNames(items...), name) {
+				return nil, fmt.Errorf("unknown objective %s, expected value, weight, risk, items or a resource", name)
+			}
+			resource := name
+			o.of = func(solution []int) float64 {
+				return resourceUsage(solution, items, resource)
+			}
+			for _, item := range items {
+				scale += item.Resources[name]
+			}
+			o.decimals = attributeDecimals(items, func(item Item) float64 { return item.Resources[resource] })
+		}
+		if scale <= 0 {
+			scale = 1
+		}
+		o.scale = scale
+		objectives = append(objectives, o)
+	}
+	if len(objectives) < 2 {
+		return nil, fmt.Errorf("at least two objectives are needed, got %d", len(objectives))
+	}
+	return objectives, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Solution of the Pareto front with its objectives in the order they were given
+type paretoPoint struct {
+	Solution   []int
+	Objectives []float64
+}
+
+// Non-dominated solutions found so far
+type paretoFront struct {
+	objectives []objective
+	points     []paretoPoint
+}
+
+// Checking whether a is at least as good as b in every objective and better in one
+func (f *paretoFront) dominates(a, b []float64) bool {
+	better := false
+	for k, o := range f.objectives {
+		x, y := a[k], b[k]
+		if !o.Maximize {
+			x, y = -x, -y
+		}
+		if x < y {
+			return false
+		}
+		if x > y {
+			better = true
+		}
+	}
+	return better
+}
+
+// Offering solution to the front, it's kept unless dominated or equal to a kept one,
+// and kept solutions it dominates are dropped. Objectives are compared rounded.
+func (f *paretoFront) offer(solution []int) {
+	values := make([]float64, len(f.objectives))
+	for k, o := range f.objectives {
+		values[k] = o.round(o.of(solution))
+	}
+	kept := f.points[:0]
+	for _, p := range f.points {
+		if f.dominates(p.Objectives, values) || equalFloats(p.Objectives, values) {
+			return
+		}
+		if !f.dominates(values, p.Objectives) {
+			kept = append(kept, p)
+		}
+	}
+	f.points = append(kept, paretoPoint{Solution: append([]int(nil), solution...), Objectives: values})
+}
+
+func equalFloats(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sorting front by the first objective, best first
+func (f *paretoFront) sort() {
+	maximize := f.objectives[0].Maximize
+	sort.SliceStable(f.points, func(a, b int) bool {
+		x, y := f.points[a].Objectives[0], f.points[b].Objectives[0]
+		if maximize {
+			return x > y
+		}
+		return x < y
+	})
+}
+
+// Simulated annealing on weighted sums of the scaled objectives, a weighting per point of the front.
+// Every accepted solution satisfying all constraints is offered to the front. Stops early with
+// the front so far when context is done.
+func solvePareto(ctx context.Context, items []Item, params Params, objectives []objective, points int) *paretoFront {
+	rnd := newRand(params)
+	front := &paretoFront{objectives: objectives}
+	epochLength := params.EpochLength
+	if epochLength < 1 {
+		epochLength = 1
+	}
+
+	for point := 0; point < points && ctx.Err() == nil; point++ {
+		// Two objectives are weighted evenly from one end of the front to the other,
+		// more of them at random
+		weights := make([]float64, len(objectives))
+		if len(objectives) == 2 && points > 1 {
+			weights[0] = float64(point) / float64(points-1)
+			weights[1] = 1 - weights[0]
+		} else {
+			total := 0.0
+			for k := range weights {
+				weights[k] = rnd.Float64()
+				total += weights[k]
+			}
+			for k := range weights {
+				weights[k] /= total
+			}
+		}
+		// Scores are scaled to the value range of temperatures of the classic solver
+		score := func(solution []int) float64 {
+			s := 0.0
+			for k, o := range objectives {
+				v := o.of(solution) / o.scale
+				if !o.Maximize {
+					v = -v
+				}
+				s += weights[k] * v
+			}
+			return 1000 * s
+		}
+
+		solution := make([]int, len(items))
+		lockItems(solution, params.Locked)
+		current := score(solution)
+		iterations := 0
+		for temp := params.MaxTemp; temp > params.MinTemp && len(items) > 0; {
+			iterations++
+			i := rnd.Intn(len(items))
+			solution[i] = 1 - solution[i]
+			lockItems(solution, params.Locked)
+			_, weight := computeEnergy(solution, items)
+			candidate := score(solution)
+			if weight <= params.MaxWeight && !items[i].Excluded && satisfiesHard(params.Constraints, solution, items) &&
+				(candidate >= current || acceptExp((candidate-current)/temp) > rnd.Float64()) {
+				current = candidate
+				if softViolation(params.Constraints, solution, items) == 0 {
+					front.offer(solution)
+				}
+			} else {
+				solution[i] = 1 - solution[i]
+				lockItems(solution, params.Locked)
+			}
+
+			if iterations%epochLength == 0 {
+				temp *= params.CoolingRate
+			}
+			if iterations%1024 == 0 && ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	front.sort()
+	return front
+}
+
+// Names of selected items of solution
+func solutionNames(solution []int, items []Item) []string {
+	names := []string{}
+	for i, included := range solution {
+		if included == 1 {
+			names = append(names, items[i].Name)
+		}
+	}
+	return names
+}
+
+// Print front as table of objectives and selected items
+func showPareto(w io.Writer, front *paretoFront, items []Item) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"#"}
+	for _, o := range front.objectives {
+		header = append(header, strings.ToUpper(o.Name))
+	}
+	fmt.Fprintln(tw, " "+strings.Join(append(header, "SELECTED"), "\t"))
+	for n, p := range front.points {
+		fields := []string{strconv.Itoa(n + 1)}
+		for k, v := range p.Objectives {
+			fields = append(fields, front.objectives[k].format(v))
+		}
+		fmt.Fprintln(tw, " "+strings.Join(append(fields, strings.Join(solutionNames(p.Solution, items), ", ")), "\t"))
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "Pareto front: %d solutions\n", len(front.points))
+}
+
+// Writing front as CSV, a row per solution with objectives and selected items separated by semicolons
+func writeParetoCSV(w io.Writer, front *paretoFront, items []Item, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+	var header []string
+	for _, o := range front.objectives {
+		header = append(header, o.Name)
+	}
+	writer.Write(append(header, "selected"))
+	for _, p := range front.points {
+		var record []string
+		for k, v := range p.Objectives {
+			record = append(record, front.objectives[k].format(v))
+		}
+		writer.Write(append(record, strings.Join(solutionNames(p.Solution, items), ";")))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Solution of the front in JSON output
+type jsonParetoPoint struct {
+	Objectives map[string]float64 `json:"objectives"`
+	Items      []string           `json:"items"`
+	Solution   []int              `json:"solution"`
+}
+
+// Writing front as indented JSON
+func writeParetoJSON(w io.Writer, front *paretoFront, items []Item) error {
+	points := []jsonParetoPoint{}
+	for _, p := range front.points {
+		objectives := map[string]float64{}
+		for k, o := range front.objectives {
+			objectives[o.Name] = p.Objectives[k]
+		}
+		points = append(points, jsonParetoPoint{Objectives: objectives, Items: solutionNames(p.Solution, items), Solution: p.Solution})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(points)
+}
+
+// Pareto subcommand: solving for two or more objectives at once, like the most value at the
+// least risk, and writing the non-dominated solutions instead of a single answer
+func runPareto(args []string) {
+	fs := flag.NewFlagSet("pareto", flag.ExitOnError)
+	input := addInputFlags(fs)
+	maxWeight := fs.Float64("capacity", 5.0, "max weight of the knapsack, input capacity is used if not given")
+	objectiveList := fs.String("objectives", "value,weight", "comma separated objectives: value is maximized, weight, risk, items and resources are minimized")
+	points := fs.Int("points", 20, "number of weightings of the objectives searched, each a run of the solver")
+	maxTemp := fs.Float64("max-temp", 1000.0, "initial temperature")
+	minTemp := fs.Float64("min-temp", 0.1, "temperature to stop at")
+	coolingRate := fs.Float64("cooling-rate", 0.95, "temperature multiplier applied after every epoch")
+	epochLength := fs.Int("epoch-length", 100, "iterations per temperature before cooling down")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	timeLimit := fs.Duration("time-limit", 0, "time budget of the solve, unlimited if zero")
+	outputFormat := fs.String("output-format", "text", "front format: text, json or csv")
+	constraints := addConstraintFlags(fs)
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	if *points < 1 {
+		invalid("-points must be positive", "points", *points)
+	}
+	if *outputFormat != "text" && *outputFormat != "json" && *outputFormat != "csv" {
+		invalid("Unknown output format, expected text, json or csv", "format", *outputFormat)
+	}
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	if instance.Capacity > 0 {
+		if err := applyConfig(fs, map[string]interface{}{"capacity": instance.Capacity}); err != nil {
+			invalid("Error in input capacity", "err", err)
+		}
+	}
+	// Refusing to solve nonsense input like solve does
+	problems := validateInstance(instance, *maxWeight)
+	for _, p := range problems {
+		p.log()
+	}
+	if hasErrors(problems) {
+		invalid("Invalid input", "problems", len(problems))
+	}
+	limits, err := constraints.list(instance, *maxWeight)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	_, items := expandQuantities(instance.Items)
+	// Items marked required are in every solution, those marked excluded in none
+	required, _ := requiredIndices(items, nil)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	params := Params{
+		MaxWeight:   *maxWeight,
+		MaxTemp:     *maxTemp,
+		MinTemp:     *minTemp,
+		CoolingRate: *coolingRate,
+		EpochLength: *epochLength,
+		Seed:        *seed,
+		Curves:      instance.Curves,
+		Synergies:   instance.Synergies,
+		Constraints: limits,
+		Locked:      required,
+	}
+	checkFeasible(items, params)
+	objectives, err := parseObjectives(*objectiveList, items, newEvaluator(items, params))
+	if err != nil {
+		invalid("Error in -objectives", "err", err)
+	}
+
+	ctx := context.Background()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+	start := time.Now()
+	front := solvePareto(ctx, items, params, objectives, *points)
+
+	switch *outputFormat {
+	case "json":
+		err = writeParetoJSON(os.Stdout, front, items)
+	case "csv":
+		err = writeParetoCSV(os.Stdout, front, items, []rune(*input.csvDelimiter)[0])
+	default:
+		fmt.Printf("Seed: %d\n", params.Seed)
+		showPareto(os.Stdout, front, items)
+		fmt.Printf("Execution time: %v\n", time.Since(start))
+	}
+	if err != nil {
+		fatal("Error while writing the front", "err", err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParetoDominates(t *testing.T) {
+	front := &paretoFront{objectives: []objective{{Name: "value", Maximize: true}, {Name: "weight"}}}
+	tests := []struct {
+		a, b []float64
+		want bool
+	}{
+		{[]float64{10, 1}, []float64{5, 2}, true},
+		{[]float64{10, 1}, []float64{10, 2}, true},
+		{[]float64{10, 2}, []float64{10, 2}, false},
+		{[]float64{10, 2}, []float64{5, 1}, false},
+		{[]float64{5, 2}, []float64{10, 1}, false},
+	}
+	for _, tt := range tests {
+		if got := front.dominates(tt.a, tt.b); got != tt.want {
+			t.Errorf("%v dominates %v: %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// Weights summed in different order are equal at weight precision, so the lighter-looking
+// solution of less value is dominated, and objectives are printed at that precision
+func TestParetoFrontRounding(t *testing.T) {
+	items := []Item{
+		{Name: "x", Weight: 0.1, Value: 6},
+		{Name: "y", Weight: 0.2, Value: 6},
+		{Name: "z", Weight: 0.3, Value: 10},
+	}
+	objectives, err := parseObjectives("value,weight", items, newEvaluator(items, Params{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := &paretoFront{objectives: objectives}
+	front.offer([]int{0, 0, 1})
+	front.offer([]int{1, 1, 0})
+	front.offer([]int{1, 0, 0})
+	front.offer([]int{0, 0, 0})
+	front.sort()
+	if len(front.points) != 3 || front.points[0].Objectives[0] != 12 || front.points[0].Objectives[1] != 0.3 {
+		t.Fatalf("front %+v", front.points)
+	}
+
+	var out bytes.Buffer
+	if err := writeParetoCSV(&out, front, items, ','); err != nil {
+		t.Fatal(err)
+	}
+	want := "value,weight,selected\n12,0.3,x;y\n6,0.1,x\n0,0.0,\n"
+	if out.String() != want {
+		t.Errorf("CSV\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+// No solution of the front dominates another one and all of them fit
+func TestSolvePareto(t *testing.T) {
+	items := []Item{
+		{Name: "a", Weight: 1, Value: 10, Risk: 0.5},
+		{Name: "b", Weight: 2, Value: 15, Risk: 0.1},
+		{Name: "c", Weight: 1.5, Value: 8, Risk: 0.2},
+		{Name: "d", Weight: 0.5, Value: 3, Risk: 0.05},
+	}
+	params := Params{MaxWeight: 3, MaxTemp: 100, MinTemp: 0.1, CoolingRate: 0.95, EpochLength: 10, Seed: 1}
+	objectives, err := parseObjectives("value,risk", items, newEvaluator(items, params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := solvePareto(context.Background(), items, params, objectives, 5)
+	if len(front.points) < 2 {
+		t.Fatalf("front of %d solutions", len(front.points))
+	}
+	for i, p := range front.points {
+		if _, weight := computeEnergy(p.Solution, items); weight > params.MaxWeight {
+			t.Errorf("%v weighs %v", p.Solution, weight)
+		}
+		for j, q := range front.points {
+			if i != j && front.dominates(p.Objectives, q.Objectives) {
+				t.Errorf("%v dominates %v", p.Objectives, q.Objectives)
+			}
+		}
+	}
+	var out bytes.Buffer
+	showPareto(&out, front, items)
+	if !strings.Contains(out.String(), "Pareto front:") {
+		t.Errorf("report\n%s", out.String())
+	}
+}
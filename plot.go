@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Point of convergence series
+type seriesPoint struct {
+	Iteration int
+	Temp      float64
+	Current   int
+	Best      int
+}
+
+// Recording convergence series of a run. Long runs are thinned out by keeping
+// every other point whenever the series grows over the limit.
+type convergenceRecorder struct {
+	limit  int
+	every  int
+	points []seriesPoint
+	last   seriesPoint
+}
+
+func newConvergenceRecorder(limit int) *convergenceRecorder {
+	return &convergenceRecorder{limit: limit, every: 1}
+}
+
+// Step hook recording the series
+func (r *convergenceRecorder) observe(s Step) {
+	current := s.CurrentValue
+	if s.Accepted {
+		current = s.CandidateValue
+	}
+	r.last = seriesPoint{Iteration: s.Iteration, Temp: s.Temp, Current: current, Best: s.BestValue}
+	if s.Iteration%r.every != 0 {
+		return
+	}
+	r.points = append(r.points, r.last)
+	if len(r.points) > r.limit {
+		kept := r.points[:0]
+		for i := 0; i < len(r.points); i += 2 {
+			kept = append(kept, r.points[i])
+		}
+		r.points = kept
+		r.every *= 2
+	}
+}
+
+// Recorded series, the last iteration included
+func (r *convergenceRecorder) series() []seriesPoint {
+	n := len(r.points)
+	if r.last.Iteration == 0 || n > 0 && r.points[n-1].Iteration == r.last.Iteration {
+		return r.points
+	}
+	return append(r.points, r.last)
+}
+
+// Writing series as CSV or SVG chart, chosen by file extension
+func writePlot(filename string, points []seriesPoint) error {
+	write := writeConvergenceSVG
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".svg":
+	case ".csv":
+		write = writeConvergenceCSV
+	default:
+		return fmt.Errorf("unsupported plot file %q, expected .svg or .csv", filename)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = write(file, points)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Writing raw series as CSV
+func writeConvergenceCSV(w io.Writer, points []seriesPoint) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"iteration", "temperature", "current_value", "best_value"})
+	for _, p := range points {
+		writer.Write([]string{strconv.Itoa(p.Iteration), formatFloat(p.Temp), strconv.Itoa(p.Current), strconv.Itoa(p.Best)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Writing chart of current and best value on the left axis and temperature
+// on the right logarithmic axis against iteration
+func writeConvergenceSVG(w io.Writer, points []seriesPoint) error {
+	const (
+		width, height          = 800, 400
+		left, right, top, down = 70, 70, 30, 40
+		plotW, plotH           = width - left - right, height - top - down
+	)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	if len(points) == 0 {
+		fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">No iterations</text>`+"\n", width/2, height/2)
+		fmt.Fprintln(bw, "</svg>")
+		return bw.Flush()
+	}
+
+	// Axis ranges
+	maxIteration := points[len(points)-1].Iteration
+	minValue, maxValue := points[0].Current, points[0].Current
+	minTemp, maxTemp := points[0].Temp, points[0].Temp
+	for _, p := range points {
+		for _, v := range []int{p.Current, p.Best} {
+			if v < minValue {
+				minValue = v
+			}
+			if v > maxValue {
+				maxValue = v
+			}
+		}
+		minTemp = math.Min(minTemp, p.Temp)
+		maxTemp = math.Max(maxTemp, p.Temp)
+	}
+	if maxValue == minValue {
+		maxValue++
+	}
+	if maxIteration == 0 {
+		maxIteration = 1
+	}
+	// Temperature axis is logarithmic, so it has to stay positive
+	minTemp = math.Max(minTemp, 1e-12)
+	maxTemp = math.Max(maxTemp, minTemp)
+	logMin, logMax := math.Log10(minTemp), math.Log10(maxTemp)
+	if logMax == logMin {
+		logMax++
+	}
+	x := func(iteration int) float64 {
+		return left + float64(iteration)/float64(maxIteration)*plotW
+	}
+	yValue := func(v int) float64 {
+		return top + (1-float64(v-minValue)/float64(maxValue-minValue))*plotH
+	}
+	yTemp := func(t float64) float64 {
+		return top + (1-(math.Log10(math.Max(t, minTemp))-logMin)/(logMax-logMin))*plotH
+	}
+	polyline := func(color string, y func(p seriesPoint) float64) {
+		fmt.Fprintf(bw, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="`, color)
+		for _, p := range points {
+			fmt.Fprintf(bw, "%.1f,%.1f ", x(p.Iteration), y(p))
+		}
+		fmt.Fprintln(bw, `"/>`)
+	}
+
+	// Frame and labels of axes
+	fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#888"/>`+"\n", left, top, plotW, plotH)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", left-5, top+10, maxValue)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", left-5, top+plotH, minValue)
+	fmt.Fprintf(bw, `<text x="%d" y="%d">%.3g</text>`+"\n", left+plotW+5, top+10, maxTemp)
+	fmt.Fprintf(bw, `<text x="%d" y="%d">%.3g</text>`+"\n", left+plotW+5, top+plotH, minTemp)
+	fmt.Fprintf(bw, `<text x="%d" y="%d">0</text>`+"\n", left, top+plotH+15)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", left+plotW, top+plotH+15, maxIteration)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">iteration</text>`+"\n", left+plotW/2, height-10)
+
+	polyline("#bbb", func(p seriesPoint) float64 { return yValue(p.Current) })
+	polyline("#2a7", func(p seriesPoint) float64 { return yValue(p.Best) })
+	polyline("#d52", func(p seriesPoint) float64 { return yTemp(p.Temp) })
+
+	// Legend
+	for i, entry := range []struct{ color, label string }{
+		{"#bbb", "current value"}, {"#2a7", "best value"}, {"#d52", "temperature (log)"},
+	} {
+		fmt.Fprintf(bw, `<line x1="%d" y1="15" x2="%d" y2="15" stroke="%s" stroke-width="3"/>`+"\n", left+i*150, left+i*150+20, entry.color)
+		fmt.Fprintf(bw, `<text x="%d" y="19">%s</text>`+"\n", left+i*150+25, entry.label)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
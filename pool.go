@@ -0,0 +1,75 @@
+package main
+
+import "sort"
+
+// Solution kept in the pool
+type PoolEntry struct {
+	Solution []int
+	Value    int
+	Weight   float64
+}
+
+// Pool of the best distinct feasible solutions found during the search, best first.
+// Solutions in the pool differ in at least minDistance items, for alternatives which are
+// not just the best one with an item swapped.
+type solutionPool struct {
+	size        int
+	minDistance int
+	entries     []PoolEntry
+	keys        map[string]bool
+}
+
+// Creating pool keeping at most size solutions at least minDistance apart
+func newSolutionPool(size, minDistance int) *solutionPool {
+	return &solutionPool{size: size, minDistance: minDistance, keys: map[string]bool{}}
+}
+
+// Offering solution to the pool, it's copied if taken
+func (p *solutionPool) offer(solution []int, value int, weight float64) {
+	// Cheap check first, most candidates are not good enough
+	if len(p.entries) == p.size && value <= p.entries[len(p.entries)-1].Value {
+		return
+	}
+	key := solutionKey(solution)
+	if p.keys[key] {
+		return
+	}
+	// Solution too near to a better one is left out, worse ones near to it give way
+	if p.minDistance > 1 {
+		kept := p.entries[:0:0]
+		for _, entry := range p.entries {
+			if hammingDistance(entry.Solution, solution) < p.minDistance {
+				if entry.Value >= value {
+					return
+				}
+				delete(p.keys, solutionKey(entry.Solution))
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		p.entries = kept
+	}
+
+	entry := PoolEntry{Solution: make([]int, len(solution)), Value: value, Weight: weight}
+	copy(entry.Solution, solution)
+	// Inserting after solutions of the same value, so earlier found ones stay first
+	i := sort.Search(len(p.entries), func(i int) bool {
+		return p.entries[i].Value < value
+	})
+	p.entries = append(p.entries, PoolEntry{})
+	copy(p.entries[i+1:], p.entries[i:])
+	p.entries[i] = entry
+	p.keys[key] = true
+
+	if len(p.entries) > p.size {
+		delete(p.keys, solutionKey(p.entries[p.size].Solution))
+		p.entries = p.entries[:p.size]
+	}
+}
+
+// Adding solutions of another pool, for pools of concurrent runs
+func (p *solutionPool) merge(other *solutionPool) {
+	for _, entry := range other.entries {
+		p.offer(entry.Solution, entry.Value, entry.Weight)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Plan for one quantile of uncertain capacity
+type quantilePlan struct {
+	Quantile float64
+	Capacity float64
+	Result   Result
+	// Items added on top of the plan of the previous quantile
+	Added []int
+}
+
+// Capacity at quantile p of normally distributed capacity
+func capacityQuantile(mean, sd, p float64) float64 {
+	return mean + sd*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+// Parsing comma separated quantiles into ascending order
+func parseQuantiles(list string) ([]float64, error) {
+	var quantiles []float64
+	for _, field := range splitNames(list) {
+		p, err := strconv.ParseFloat(field, 64)
+		if err != nil || p <= 0 || p >= 1 {
+			return nil, fmt.Errorf("quantile must be a number between 0 and 1, got %q", field)
+		}
+		quantiles = append(quantiles, p)
+	}
+	sort.Float64s(quantiles)
+	return quantiles, nil
+}
+
+// Solving for every quantile of capacity from the smallest one, each plan locking in
+// the items of the previous one, so that plans are nested and a larger capacity
+// only adds items to a smaller one
+func solveQuantiles(ctx context.Context, o *Orchestrator, items []Item, params Params, restarts int,
+	quantiles []float64, sd float64, solver Solver) []quantilePlan {
+	mean := params.MaxWeight
+	var plans []quantilePlan
+	var previous []int
+	for _, p := range quantiles {
+		config := params
+		config.MaxWeight = capacityQuantile(mean, sd, p)
+		config.Locked = append(append([]int(nil), params.Locked...), previous...)
+
+		result := o.Restarts(ctx, items, config, restarts, solver)
+		plan := quantilePlan{Quantile: p, Capacity: config.MaxWeight, Result: result}
+		selected := map[int]bool{}
+		for _, i := range previous {
+			selected[i] = true
+		}
+		previous = previous[:0:0]
+		for i, included := range result.Solution {
+			if included == 1 {
+				previous = append(previous, i)
+				if !selected[i] {
+					plan.Added = append(plan.Added, i)
+				}
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+// Print nested plans, each one by items added to the previous one
+func showQuantilePlans(w io.Writer, plans []quantilePlan, items []Item) {
+	fmt.Fprintln(w, "Nested plans by capacity quantile:")
+	for n, plan := range plans {
+		fmt.Fprintf(w, "Quantile %g, capacity %f: value %d, weight %f\n",
+			plan.Quantile, plan.Capacity, plan.Result.Value, plan.Result.Weight)
+		label := "Items"
+		if n > 0 {
+			label = "Adding"
+		}
+		var names []string
+		for _, i := range plan.Added {
+			names = append(names, items[i].Name)
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(w, " %s: %s\n", label, strings.Join(names, ", "))
+		}
+	}
+	fmt.Fprintf(w, "-------------------------------------------------------------")
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Plans adding nothing to the previous one have no list of added items
+func TestShowQuantilePlans(t *testing.T) {
+	items := []Item{{Name: "tent", Weight: 2, Value: 10}, {Name: "stove", Weight: 1, Value: 4}}
+	plans := []quantilePlan{
+		{Quantile: 0.1, Capacity: 2, Result: Result{Solution: []int{1, 0}, Value: 10, Weight: 2}, Added: []int{0}},
+		{Quantile: 0.5, Capacity: 2.5, Result: Result{Solution: []int{1, 0}, Value: 10, Weight: 2}},
+		{Quantile: 0.9, Capacity: 3, Result: Result{Solution: []int{1, 1}, Value: 14, Weight: 3}, Added: []int{1}},
+	}
+	var out bytes.Buffer
+	showQuantilePlans(&out, plans, items)
+	if strings.Count(out.String(), "Adding:") != 1 || !strings.Contains(out.String(), " Adding: stove\n") ||
+		!strings.Contains(out.String(), " Items: tent\n") {
+		t.Errorf("plans\n%s", out.String())
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Items with quantities expanded into single copies, so that solvers decide on every copy
+// as on any other item. Copies keep the name of their item, solutions name them by it.
+type itemCopies struct {
+	stock []Item
+	// Index of the stock item of every copy
+	original []int
+}
+
+// Expanding items into copies, nil if no item has more than one
+func expandQuantities(items []Item) (*itemCopies, []Item) {
+	expanded := false
+	for _, item := range items {
+		expanded = expanded || item.Quantity > 1
+	}
+	if !expanded {
+		return nil, items
+	}
+	c := &itemCopies{stock: items}
+	var copies []Item
+	for i, item := range items {
+		single := item
+		single.Quantity = 0
+		for n := 0; n < max(item.Quantity, 1); n++ {
+			copies = append(copies, single)
+			c.original = append(c.original, i)
+		}
+	}
+	return c, copies
+}
+
+// Number of copies taken of every stock item
+func (c *itemCopies) counts(solution []int) []int {
+	counts := make([]int, len(c.stock))
+	for i, included := range solution {
+		counts[c.original[i]] += included
+	}
+	return counts
+}
+
+// Checking that unbounded copies of items can't have unbounded value
+func checkUnbounded(items []Item) error {
+	for _, item := range items {
+		if item.Weight <= 0 && item.Value > 0 {
+			return fmt.Errorf("item %s weighs nothing, unbounded copies of it have unbounded value", item.Name)
+		}
+	}
+	return nil
+}
+
+// Selection of the unbounded problem, a count of copies of every item
+// with totals kept up to date
+type countState struct {
+	items  []Item
+	counts []int
+	value  int
+	weight float64
+}
+
+func (s *countState) add(i, n int) {
+	s.counts[i] += n
+	s.value += n * s.items[i].Value
+	s.weight += float64(n) * s.items[i].Weight
+}
+
+// Taking as many copies as fit of every item in order of value density
+func (s *countState) greedy(capacity float64) {
+	for _, i := range densityOrder(s.items) {
+		if item := s.items[i]; item.Value > 0 && item.Weight > 0 {
+			if n := int(math.Floor((capacity - s.weight) / item.Weight)); n > 0 {
+				s.add(i, n)
+			}
+		}
+	}
+}
+
+// Simulated annealing over counts of the unbounded problem: candidates take one more
+// copy of an item or put one back. Stops early with the best counts so far when context
+// is done. Solution of the result is the counts.
+func unboundedSolver(ctx context.Context, items []Item, params Params) Result {
+	start := time.Now()
+	rnd := newRand(params)
+	s := &countState{items: items, counts: make([]int, len(items))}
+	if params.Init != "empty" {
+		s.greedy(params.MaxWeight)
+	}
+	best := append([]int(nil), s.counts...)
+	bestValue, bestIteration := s.value, 0
+
+	epochLength := max(params.EpochLength, 1)
+	iterations := 0
+	for temp := params.MaxTemp; temp > params.MinTemp && len(items) > 0; {
+		iterations++
+		current := s.value
+		i := rnd.Intn(len(items))
+		n := 1
+		if rnd.Intn(2) == 0 {
+			n = -1
+		}
+		// Taking only copies which fit and are worth something, putting back only taken ones
+		if n > 0 && items[i].Value > 0 && s.weight+items[i].Weight <= params.MaxWeight+1e-9 ||
+			n < 0 && s.counts[i] > 0 {
+			s.add(i, n)
+			if candidateIsBetter(current, s.value, temp) > rnd.Float64() {
+				if s.value > bestValue {
+					best = append(best[:0], s.counts...)
+					bestValue, bestIteration = s.value, iterations
+				}
+			} else {
+				s.add(i, -n)
+			}
+		}
+
+		if iterations%epochLength == 0 {
+			temp *= params.CoolingRate
+		}
+		if iterations > 1000000 {
+			slog.Warn("Too many iterations, stopping early")
+			break
+		}
+		if iterations%1024 == 0 && ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Weight is summed again, so it doesn't carry rounding errors of all the moves
+	result := Result{Solution: best, Value: bestValue, Iterations: iterations, BestIteration: bestIteration,
+		Seed: params.Seed, Algorithm: "unbounded-annealing", Duration: time.Since(start)}
+	for i, count := range best {
+		result.Weight += float64(count) * items[i].Weight
+	}
+	return result
+}
+
+// Flags solve takes together with -unbounded, others need 0/1 selections
+var unboundedFlags = map[string]bool{
+	"input": true, "input-timeout": true, "input-max-size": true, "format": true, "strict": true,
+	"csv-delimiter": true, "xlsx-sheet": true, "xlsx-columns": true, "config": true, "no-session": true,
+	"capacity": true, "max-temp": true, "min-temp": true, "cooling-rate": true, "epoch-length": true,
+	"init": true, "seed": true, "rng": true, "restarts": true, "workers": true, "time-limit": true,
+	"output-format": true, "output": true, "sort": true, "no-color": true, "unbounded": true,
+	"v": true, "vv": true, "quiet": true,
+}
+
+// Solving the unbounded problem for solve, with the params of the solve flags
+// and the report in the given format. Returns exit code of solve.
+func solveUnbounded(fs *flag.FlagSet, inputFile string, instance *Instance, params Params, restarts, workers int,
+	timeLimit time.Duration, format, output string, order itemOrder, noColor bool, delimiter rune) int {
+	var unsupported []string
+	fs.Visit(func(f *flag.Flag) {
+		if !unboundedFlags[f.Name] {
+			unsupported = append(unsupported, "-"+f.Name)
+		}
+	})
+	if len(unsupported) > 0 {
+		invalid("-unbounded can't be combined with other solve options", "flags", strings.Join(unsupported, ","))
+	}
+	unsupported, err := extraConstraints(instance)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	for _, item := range instance.Items {
+		if item.Required || item.Excluded {
+			unsupported = append(unsupported, "required and excluded items")
+			break
+		}
+	}
+	if len(unsupported) > 0 {
+		invalid("Unbounded problem can't take constraints, curves or synergies", "constraints", strings.Join(unsupported, ","))
+	}
+	if err := checkUnbounded(instance.Items); err != nil {
+		invalid("Error in unbounded items", "err", err)
+	}
+	if params.Seed == 0 {
+		params.Seed = time.Now().UnixNano()
+	}
+	if params.RNG != "default" && params.RNG != "pcg" {
+		invalid("Unknown random generator", "rng", params.RNG)
+	}
+	if params.Init != "greedy" && params.Init != "empty" {
+		invalid("Unbounded problem starts greedy or empty", "init", params.Init)
+	}
+	if format != "text" && format != "json" && format != "csv" {
+		invalid("Unbounded problem is reported as text, json or csv", "format", format)
+	}
+
+	start := time.Now()
+	orchestrator := &Orchestrator{Budget: timeLimit, Workers: workers}
+	result := orchestrator.Restarts(context.Background(), instance.Items, params, restarts, unboundedSolver)
+	duration := time.Since(start)
+
+	// Reports list taken items with their counts as quantities
+	counts := result.Solution
+	result.Counts = counts
+	items := make([]Item, len(instance.Items))
+	for i, item := range instance.Items {
+		items[i] = item
+		items[i].Quantity = counts[i]
+	}
+	instance.Items = items
+
+	report := io.Writer(os.Stdout)
+	var reportFile *os.File
+	if output != "-" {
+		if reportFile, err = os.Create(output); err != nil {
+			fatal("Error while writing the report", "err", err)
+		}
+		report = reportFile
+	}
+	bound := upperBoundUnbounded(items, params.MaxWeight)
+	switch format {
+	case "json":
+		jsonReport := newJSONReport(inputFile, instance, params, result, order, duration)
+		jsonReport.UpperBound, jsonReport.Gap = bound, boundGap(result.Value, bound)
+		err = writeJSONReport(report, jsonReport)
+	case "csv":
+		err = writeCSVReport(report, result, items, order, delimiter)
+	default:
+		writeTextReport(report, result, items, nil, order, params.MaxWeight, ownerValuation{}, duration,
+			colorEnabled(report, noColor))
+	}
+	if reportFile != nil {
+		if closeErr := reportFile.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fatal("Error while writing the report", "err", err)
+	}
+
+	// Notes besides the report go to standard error when it has to stay parseable, like in solve
+	notes := io.Writer(os.Stdout)
+	if format != "text" && output == "-" {
+		notes = os.Stderr
+	}
+	showBound(notes, bound, result)
+	return exitOK
+}
+
+// Upper bound of the unbounded problem, the capacity filled with the densest item
+func upperBoundUnbounded(items []Item, capacity float64) float64 {
+	bound := 0.0
+	for _, item := range items {
+		if item.Value > 0 && item.Weight > 0 {
+			bound = math.Max(bound, capacity*density(item))
+		}
+	}
+	return bound
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+// Counts grow and shrink by single copies and never exceed the capacity
+func TestUnboundedSolver(t *testing.T) {
+	items := []Item{
+		{Name: "c1", Weight: 1, Value: 1},
+		{Name: "c3", Weight: 3, Value: 5},
+		{Name: "c4", Weight: 4, Value: 6},
+		{Name: "heavy", Weight: 20, Value: 100},
+	}
+	params := Params{MaxWeight: 11, MaxTemp: 10, MinTemp: 0.1, CoolingRate: 0.99, EpochLength: 10, Init: "empty", Seed: 1}
+	result := unboundedSolver(context.Background(), items, params)
+	weight, value := 0.0, 0
+	for i, count := range result.Solution {
+		weight += float64(count) * items[i].Weight
+		value += count * items[i].Value
+	}
+	if weight > params.MaxWeight || weight != result.Weight || value != result.Value {
+		t.Errorf("counts %v weigh %v and are worth %d, result %v and %d", result.Solution, weight, value, result.Weight, result.Value)
+	}
+	// Three copies of c3 and two of c1
+	if result.Value != 17 {
+		t.Errorf("value %d, want 17 of %v", result.Value, result.Solution)
+	}
+}
+
+// Counts reach the optimum dynamic programming finds over copies of every item, as many as fit
+func TestUnboundedOptimum(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 10; n++ {
+		capacity := float64(10 + rnd.Intn(20))
+		items := make([]Item, 3+rnd.Intn(4))
+		stock := make([]Item, len(items))
+		for i := range items {
+			items[i] = Item{Name: string(rune('a' + i)), Weight: float64(1 + rnd.Intn(9)), Value: 1 + rnd.Intn(30)}
+			stock[i] = items[i]
+			stock[i].Quantity = int(capacity / items[i].Weight)
+		}
+		_, copies := expandQuantities(stock)
+		optimum := dpSolver(context.Background(), copies, Params{MaxWeight: capacity})
+
+		params := Params{MaxWeight: capacity, MaxTemp: 100, MinTemp: 0.01, CoolingRate: 0.99, EpochLength: 50,
+			Init: "greedy", Seed: int64(n + 1)}
+		result := unboundedSolver(context.Background(), items, params)
+		if result.Value != optimum.Value {
+			t.Errorf("instance %d: counts %v are worth %d, optimum is %d", n, result.Solution, result.Value, optimum.Value)
+		}
+	}
+}
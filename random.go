@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"time"
+)
+
+// Creating random generator for a run: caller-provided source, or configured generator
+// seeded with fixed seed or clock
+func newRand(params Params) *rand.Rand {
+	if params.Source != nil {
+		return rand.New(params.Source)
+	}
+
+	// Randomizing seed for random unless fixed seed is given
+	seed := params.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if params.RNG == "pcg" {
+		return rand.New(newPCGSource(uint64(seed)))
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// Adapter making math/rand/v2 generators (PCG, ChaCha8) usable as Params.Source
+type sourceV2 struct {
+	src randv2.Source
+}
+
+// Creating random source backed by PCG generator
+func newPCGSource(seed uint64) rand.Source {
+	return sourceV2{src: randv2.NewPCG(seed, seed^0x9e3779b97f4a7c15)}
+}
+
+func (s sourceV2) Int63() int64 {
+	return int64(s.src.Uint64() >> 1)
+}
+
+func (s sourceV2) Uint64() uint64 {
+	return s.src.Uint64()
+}
+
+// Reseeding is not part of math/rand/v2 Source interface, generator keeps its state
+func (s sourceV2) Seed(int64) {}
@@ -0,0 +1,91 @@
+package main
+
+// Instance reduced by removing items, with mapping back to the original items
+type reduction struct {
+	items []Item
+	// Index of every kept item in the original items
+	original []int
+	removed  int
+	total    int
+}
+
+// Checking if item a dominates item b: it's not heavier or less certain of weight, not less valuable, not riskier,
+// consumes no more of any resource and counts the same for curves, fairness and groups.
+// Equal items are ordered by index, so that of two copies only the later one is dominated.
+func dominates(a, b Item, ia, ib int) bool {
+	if a.Category != b.Category || a.Owner != b.Owner || a.Group != b.Group {
+		return false
+	}
+	if a.Weight > b.Weight || a.Value < b.Value || a.Risk > b.Risk || a.WeightSD > b.WeightSD {
+		return false
+	}
+	equal := a.Weight == b.Weight && a.Value == b.Value && a.Risk == b.Risk && a.WeightSD == b.WeightSD
+	for _, resource := range resourceNames(a, b) {
+		if a.Resources[resource] > b.Resources[resource] {
+			return false
+		}
+		equal = equal && a.Resources[resource] == b.Resources[resource]
+	}
+	if equal {
+		return ia < ib
+	}
+	return true
+}
+
+// Removing dominated items which can't be in any optimal solution.
+// Dominated item is only worth selecting together with all its dominators, otherwise
+// it can be exchanged for one of them without loss. So if it doesn't fit together with
+// its dominators, it's never needed. Items dominated by a single other one may still
+// be needed, unlike in the unbounded problem.
+func reduceDominated(items []Item, maxWeight float64) *reduction {
+	r := &reduction{total: len(items)}
+	for j, item := range items {
+		weight := item.Weight
+		for i, other := range items {
+			if i != j && dominates(other, item, i, j) {
+				weight += other.Weight
+			}
+		}
+		if weight > maxWeight {
+			r.removed++
+			continue
+		}
+		r.items = append(r.items, item)
+		r.original = append(r.original, j)
+	}
+	return r
+}
+
+// Mapping solution of the reduced instance to the original items
+func (r *reduction) expand(solution []int) []int {
+	expanded := make([]int, r.total)
+	for i, included := range solution {
+		expanded[r.original[i]] = included
+	}
+	return expanded
+}
+
+// Mapping result and its pool to the original items
+func (r *reduction) expandResult(result Result) Result {
+	result.Solution = r.expand(result.Solution)
+	if result.Pool != nil {
+		pool := newSolutionPool(result.Pool.size, result.Pool.minDistance)
+		for _, entry := range result.Pool.entries {
+			pool.offer(r.expand(entry.Solution), entry.Value, entry.Weight)
+		}
+		result.Pool = pool
+	}
+	return result
+}
+
+// Chaining reduction of already reduced items, nil reduction keeps all items
+func (r *reduction) then(next *reduction) *reduction {
+	if r == nil {
+		return next
+	}
+	original := make([]int, len(next.original))
+	for i, j := range next.original {
+		original[i] = r.original[j]
+	}
+	return &reduction{items: next.items, original: original, removed: r.removed + next.removed, total: r.total}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Opening the report output, - is standard output. Returned function closes the file.
+func createReport(output string) (io.Writer, func() error, error) {
+	if output == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// Writing result in text format, colored when color is on
+func writeTextReport(w io.Writer, result Result, items []Item, copies *itemCopies, order itemOrder, capacity float64,
+	valuation ownerValuation, duration time.Duration, color bool) {
+	fmt.Fprintf(w, "Seed: %d\n", result.Seed)
+	fmt.Fprintf(w, "Best solution: %v\n", result.Solution)
+	// Copies count taken stock items, without them solution counts the items themselves
+	var counts []int
+	if copies != nil && result.Counts != nil {
+		fmt.Fprintf(w, "Copies of every item: %v\n", result.Counts)
+	} else {
+		counts = result.Counts
+	}
+	showKnapsack(w, order.selected(result.Solution, items), items, copies, counts, capacity, color)
+	showOwners(w, result.Solution, items, valuation)
+	fmt.Fprintf(w, "Total value: %s\n", colorize(color, colorBold, strconv.Itoa(result.Value)))
+	if result.Violation > 0 {
+		fmt.Fprintf(w, "Warning: no solution satisfying all constraints found, violation: %.4f\n", result.Violation)
+	}
+
+	// Script execution time
+	fmt.Fprintf(w, "Execution time: %v\n", duration)
+	fmt.Fprintf(w, "-------------------------------------------------------------")
+}
+
+// Writing selected items as CSV for spreadsheets, with totals in the footer row
+func writeCSVReport(w io.Writer, result Result, items []Item, order itemOrder, delimiter rune) error {
+	var selected []Item
+	for _, i := range order.selected(result.Solution, items) {
+		selected = append(selected, items[i])
+	}
+	if err := writeItemsCSV(w, selected, delimiter); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+	// Totals under their columns, as wide as the header, name, weight and value come first
+	footer := make([]string, len(itemsCSVColumns(selected)))
+	footer[0], footer[1], footer[2] = "Total", formatFloat(result.Weight), strconv.Itoa(result.Value)
+	writer.Write(footer)
+	writer.Flush()
+	return writer.Error()
+}
+
+// Result of solve as a single JSON document for downstream tooling
+type jsonReport struct {
+	Input      string  `json:"input"`
+	Seed       int64   `json:"seed"`
+	Value      int     `json:"value"`
+	Weight     float64 `json:"weight"`
+	Capacity   float64 `json:"capacity"`
+	Items      []Item  `json:"items"`
+	Solution   []int   `json:"solution"`
+	Counts     []int   `json:"counts,omitempty"`
+	Violation  float64 `json:"violation,omitempty"`
+	Iterations int     `json:"iterations"`
+	// Upper bound of the value and the share solution falls short of it
+	UpperBound  float64  `json:"upper_bound"`
+	Gap         float64  `json:"gap"`
+	Duration    string   `json:"duration"`
+	Seconds     float64  `json:"duration_seconds"`
+	Params      Params   `json:"params"`
+	Constraints []string `json:"constraints,omitempty"`
+	// Experimental features enabled for the run, as in manifests
+	Features []string `json:"features,omitempty"`
+}
+
+func newJSONReport(inputFile string, instance *Instance, params Params, result Result, order itemOrder,
+	duration time.Duration) jsonReport {
+	report := jsonReport{
+		Input:      inputFile,
+		Seed:       result.Seed,
+		Value:      result.Value,
+		Weight:     result.Weight,
+		Capacity:   params.MaxWeight,
+		Items:      []Item{},
+		Solution:   result.Solution,
+		Counts:     result.Counts,
+		Violation:  result.Violation,
+		Iterations: result.Iterations,
+		Duration:   duration.String(),
+		Seconds:    duration.Seconds(),
+		Params:     params,
+	}
+	for _, i := range order.selected(result.Solution, instance.Items) {
+		report.Items = append(report.Items, instance.Items[i])
+	}
+	for _, c := range params.Constraints {
+		report.Constraints = append(report.Constraints, c.Name())
+	}
+	return report
+}
+
+// Writing report as indented JSON
+func writeJSONReport(w io.Writer, report jsonReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
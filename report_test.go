@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+// Footer has totals under weight and value, and as many fields as the header
+func TestCSVReportFooter(t *testing.T) {
+	items := []Item{
+		{Name: "tent", Weight: 2, Value: 30, WeightSD: 0.1, Category: "shelter"},
+		{Name: "pegs", Weight: 0.25, Value: 1},
+		{Name: "rope", Weight: 0.5, Value: 5, Category: "tools"},
+	}
+	result := Result{Solution: []int{1, 0, 1}, Value: 35, Weight: 2.5}
+	var buf bytes.Buffer
+	if err := writeCSVReport(&buf, result, items, itemOrder{}, ';'); err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(&buf)
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	want := [][]string{
+		{"name", "weight", "value", "weight_sd", "category"},
+		{"tent", "2", "30", "0.1", "shelter"},
+		{"rope", "0.5", "5", "0", "tools"},
+		{"Total", "2.5", "35", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("report\n%q\nwant\n%q", records, want)
+	}
+}
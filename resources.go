@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cap on total consumption of a resource besides weight, like volume or power
+type resourceLimit struct {
+	Resource string
+	Capacity float64
+}
+
+func (c resourceLimit) Name() string {
+	return fmt.Sprintf("%s <= %g", c.Resource, c.Capacity)
+}
+
+func (c resourceLimit) Satisfied(solution []int, items []Item) bool {
+	return resourceUsage(solution, items, c.Resource) <= c.Capacity
+}
+
+// Total consumption of resource by selected items
+func resourceUsage(solution []int, items []Item, resource string) float64 {
+	var usage neumaierSum
+	for i, included := range solution {
+		if included == 1 {
+			usage.add(items[i].Resources[resource])
+		}
+	}
+	return usage.value()
+}
+
+// Resources of capacities in sorted order, for stable output
+func sortedResources(capacities map[string]float64) []string {
+	names := make([]string, 0, len(capacities))
+	for name := range capacities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Limits of resources, sorted by resource name so that reports are stable
+func resourceConstraints(capacities map[string]float64) []Constraint {
+	names := sortedResources(capacities)
+	constraints := make([]Constraint, len(names))
+	for i, name := range names {
+		constraints[i] = resourceLimit{Resource: name, Capacity: capacities[name]}
+	}
+	return constraints
+}
+
+// Parsing comma separated resource capacities like "volume=3,power=10"
+func parseResourceCapacities(spec string) (map[string]float64, error) {
+	capacities := map[string]float64{}
+	for _, field := range splitNames(spec) {
+		name, value, ok := strings.Cut(field, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("resource capacity must be name=capacity, got %q", field)
+		}
+		capacity, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(capacity) || capacity < 0 {
+			return nil, fmt.Errorf("capacity of resource %s must be a non-negative number, got %q", name, value)
+		}
+		capacities[name] = capacity
+	}
+	return capacities, nil
+}
+
+// Names of resources consumed by any of the items, sorted
+func resourceNames(items ...Item) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, item := range items {
+		for name := range item.Resources {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Print consumption of every limited resource against its capacity
+func showResources(w io.Writer, solution []int, items []Item, constraints []Constraint) {
+	first := true
+	for _, c := range constraints {
+		if limit, ok := c.(resourceLimit); ok {
+			if first {
+				fmt.Fprintln(w)
+				first = false
+			}
+			usage := resourceUsage(solution, items, limit.Resource)
+			used := 0.0
+			if limit.Capacity > 0 {
+				used = 100 * usage / limit.Capacity
+			}
+			fmt.Fprintf(w, "Resource %s: %s of %s (%.1f%% used)\n", limit.Resource, formatFloat(usage), formatFloat(limit.Capacity), used)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Result of one run as written to the result log
+type resultRecord struct {
+	Run        int       `json:"run"`
+	Seed       int64     `json:"seed"`
+	Value      int       `json:"value"`
+	Weight     float64   `json:"weight"`
+	Violation  float64   `json:"violation,omitempty"`
+	Iterations int       `json:"iterations"`
+	Duration   string    `json:"duration"`
+	Solution   []int     `json:"solution"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Summary of runs finished so far in this invocation
+type resultSummary struct {
+	Runs      int           `json:"runs"`
+	Best      *resultRecord `json:"best"`
+	StartedAt time.Time     `json:"started_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Append-only NDJSON log of run results, written as soon as every run finishes,
+// with summary file refreshed after every run. Completed runs survive a crash of the rest.
+type resultLog struct {
+	mu          sync.Mutex
+	file        *os.File
+	summaryFile string
+	summary     resultSummary
+}
+
+// Opening result log for appending, summary goes next to it with .summary.json suffix
+func openResultLog(filename string) (*resultLog, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &resultLog{
+		file:        file,
+		summaryFile: filename + ".summary.json",
+		summary:     resultSummary{StartedAt: time.Now()},
+	}, nil
+}
+
+// Writing result of finished run, safe for concurrent runs
+func (l *resultLog) record(result Result) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.summary.Runs++
+	record := &resultRecord{
+		Run:        l.summary.Runs,
+		Seed:       result.Seed,
+		Value:      result.Value,
+		Weight:     result.Weight,
+		Violation:  result.Violation,
+		Iterations: result.Iterations,
+		Duration:   result.Duration.String(),
+		Solution:   result.Solution,
+		FinishedAt: time.Now(),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	// Making sure the line is on disk before the next run finishes
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+
+	best := l.summary.Best
+	if best == nil || record.Violation < best.Violation || record.Violation == best.Violation && record.Value > best.Value {
+		l.summary.Best = record
+	}
+	l.summary.UpdatedAt = record.FinishedAt
+	return l.writeSummary()
+}
+
+// Replacing summary file atomically, so it's never seen half-written
+func (l *resultLog) writeSummary() error {
+	data, err := json.MarshalIndent(l.summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := l.summaryFile + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, l.summaryFile)
+}
+
+func (l *resultLog) Close() error {
+	return l.file.Close()
+}
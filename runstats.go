@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// Statistics of the objective over independent runs of a stochastic solver
+type runStatistics struct {
+	Runs   int
+	Best   int
+	Worst  int
+	Mean   float64
+	Median float64
+	StdDev float64
+	// Runs satisfying all constraints
+	Feasible     int
+	MeanDuration time.Duration
+}
+
+func newRunStatistics(results []Result) runStatistics {
+	stats := runStatistics{Runs: len(results)}
+	if len(results) == 0 {
+		return stats
+	}
+	values := make([]float64, len(results))
+	var total time.Duration
+	for i, result := range results {
+		values[i] = float64(result.Value)
+		total += result.Duration
+		if result.Violation == 0 {
+			stats.Feasible++
+		}
+	}
+	sort.Float64s(values)
+	stats.Worst, stats.Best = int(values[0]), int(values[len(values)-1])
+	if n := len(values); n%2 == 1 {
+		stats.Median = values[n/2]
+	} else {
+		stats.Median = (values[n/2-1] + values[n/2]) / 2
+	}
+	var variance float64
+	stats.Mean, variance = meanVariance(values)
+	stats.StdDev = math.Sqrt(variance)
+	stats.MeanDuration = total / time.Duration(len(results))
+	return stats
+}
+
+// Print statistics of runs, the best run itself is the reported solution
+func showRunStatistics(w io.Writer, stats runStatistics) {
+	fmt.Fprintf(w, "\nStatistics over %d runs:\n", stats.Runs)
+	fmt.Fprintf(w, " Best value: %d\n", stats.Best)
+	fmt.Fprintf(w, " Worst value: %d\n", stats.Worst)
+	fmt.Fprintf(w, " Mean value: %.2f\n", stats.Mean)
+	fmt.Fprintf(w, " Median value: %.1f\n", stats.Median)
+	fmt.Fprintf(w, " Standard deviation: %.2f\n", stats.StdDev)
+	if stats.Feasible < stats.Runs {
+		fmt.Fprintf(w, " Runs satisfying all constraints: %d\n", stats.Feasible)
+	}
+	fmt.Fprintf(w, " Mean run time: %v\n", stats.MeanDuration)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Relative change of weight, value or capacity still considered the same in similar instances
+const similarTolerance = 0.1
+
+// Run kept in the run store: its manifest and the instance it solved
+type storedRun struct {
+	Manifest *Manifest `json:"manifest"`
+	Capacity float64   `json:"capacity"`
+	Items    []Item    `json:"items"`
+	// File the run was loaded from
+	file string
+}
+
+// Previous run of an instance similar to the current one
+type similarRun struct {
+	Run *storedRun
+	// Share of items which are the same in both instances, up to small changes
+	Similarity float64
+}
+
+// Saving run into the store directory, one JSON file per run
+func saveRun(dir string, manifest *Manifest, capacity float64, items []Item) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	hash := manifest.InputSHA256
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	name := fmt.Sprintf("%s-%s.json", manifest.StartedAt.UTC().Format("20060102T150405.000000000"), hash)
+	filename := filepath.Join(dir, name)
+
+	data, err := json.MarshalIndent(storedRun{Manifest: manifest, Capacity: capacity, Items: items}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return filename, os.WriteFile(filename, data, 0644)
+}
+
+// Loading all runs of the store, missing store has no runs
+func loadRuns(dir string) ([]*storedRun, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*storedRun
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		run := &storedRun{file: filename}
+		if err := json.Unmarshal(data, run); err != nil || run.Manifest == nil {
+			return nil, fmt.Errorf("%s: not a stored run", filename)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Key matching items of two instances: name, or position for unnamed items
+func itemKey(i int, item Item) string {
+	if item.Name == "" {
+		return fmt.Sprintf("#%d", i)
+	}
+	return item.Name
+}
+
+// Checking if values differ by at most the tolerance relative to the larger one
+func nearlyEqual(a, b float64) bool {
+	largest := math.Max(math.Abs(a), math.Abs(b))
+	return largest == 0 || math.Abs(a-b) <= similarTolerance*largest
+}
+
+// Share of items present in both instances with nearly the same weight and value,
+// among items of either instance
+func instanceSimilarity(a, b []Item) float64 {
+	byKey := make(map[string]Item, len(a))
+	for i, item := range a {
+		byKey[itemKey(i, item)] = item
+	}
+	same, common := 0, 0
+	for i, item := range b {
+		other, ok := byKey[itemKey(i, item)]
+		if !ok {
+			continue
+		}
+		common++
+		if nearlyEqual(item.Weight, other.Weight) && nearlyEqual(float64(item.Value), float64(other.Value)) {
+			same++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 1
+	}
+	return float64(same) / float64(union)
+}
+
+// Finding stored runs of instances similar to the given one, most similar first
+func findSimilarRuns(runs []*storedRun, items []Item, capacity, threshold float64) []similarRun {
+	var similar []similarRun
+	for _, run := range runs {
+		if !nearlyEqual(run.Capacity, capacity) {
+			continue
+		}
+		if similarity := instanceSimilarity(run.Items, items); similarity >= threshold {
+			similar = append(similar, similarRun{Run: run, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	return similar
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Change of solution made possible by more capacity: bringing in an excluded item,
+// optionally in place of an included one
+type capacityOption struct {
+	// Capacity needed on top of the current one
+	Extra  float64
+	Add    int
+	Remove int // -1 if nothing is removed
+	Gain   int
+}
+
+// Cheapest capacity increase for every excluded item to improve solution, by the item
+// alone or swapped for one included item, smallest increases first
+func capacityOptions(solution []int, items []Item, params Params) []capacityOption {
+	candidate := append([]int(nil), solution...)
+	eval := newEvaluator(items, params)
+	value, weight := eval.evaluate(candidate)
+	locked := make([]bool, len(items))
+	for _, i := range params.Locked {
+		locked[i] = true
+	}
+
+	// Values of curved categories and synergies depend on the other items, so they are evaluated whole
+	change := func() (gain int, extra float64, ok bool) {
+		if !satisfiesHard(params.Constraints, candidate, items) ||
+			softViolation(params.Constraints, candidate, items) > 0 {
+			return 0, 0, false
+		}
+		v, w := eval.evaluate(candidate)
+		return v - value, w - params.MaxWeight, true
+	}
+	linear := len(params.Curves) == 0 && len(params.Synergies) == 0 && len(params.Constraints) == 0
+
+	var options []capacityOption
+	for add, included := range solution {
+		if included == 1 {
+			continue
+		}
+		best := capacityOption{Add: add, Remove: -1}
+		found := false
+		consider := func(remove int) {
+			var gain int
+			var extra float64
+			if linear {
+				gain, extra = items[add].Value, weight+items[add].Weight-params.MaxWeight
+				if remove >= 0 {
+					gain, extra = gain-items[remove].Value, extra-items[remove].Weight
+				}
+			} else {
+				candidate[add] = 1
+				if remove >= 0 {
+					candidate[remove] = 0
+				}
+				var ok bool
+				gain, extra, ok = change()
+				candidate[add] = 0
+				if remove >= 0 {
+					candidate[remove] = 1
+				}
+				if !ok {
+					return
+				}
+			}
+			// Changes fitting already are left to the solver
+			if gain <= 0 || extra <= 1e-9 {
+				return
+			}
+			if !found || extra < best.Extra || extra == best.Extra && gain > best.Gain {
+				best.Extra, best.Remove, best.Gain = extra, remove, gain
+				found = true
+			}
+		}
+		consider(-1)
+		for remove, in := range solution {
+			if in == 1 && !locked[remove] {
+				consider(remove)
+			}
+		}
+		if found {
+			options = append(options, best)
+		}
+	}
+	sort.SliceStable(options, func(a, b int) bool {
+		if options[a].Extra != options[b].Extra {
+			return options[a].Extra < options[b].Extra
+		}
+		return options[a].Gain > options[b].Gain
+	})
+	return options
+}
+
+// Print the smallest capacity increases changing the solution and what they would bring in
+func showSensitivity(w io.Writer, options []capacityOption, items []Item, limit int) {
+	if len(options) == 0 {
+		fmt.Fprintf(w, "\nNo capacity increase brings in an item improving the solution\n")
+		return
+	}
+	fmt.Fprintf(w, "\nCapacity sensitivity, smallest increases changing the solution first:\n")
+	decimals := weightDecimals(items)
+	for n, option := range options {
+		if n == limit {
+			break
+		}
+		fmt.Fprintf(w, " +%.*f: add %s", decimals, option.Extra, items[option.Add].Name)
+		if option.Remove >= 0 {
+			fmt.Fprintf(w, " in place of %s", items[option.Remove].Name)
+		}
+		fmt.Fprintf(w, ", value %+d (%.2f per unit)\n", option.Gain, float64(option.Gain)/option.Extra)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Directory keeping sessions and the name of the current one, in working directory
+const sessionRoot = ".knapsack"
+
+// Named workspace of iterative planning: the problem, locked items, solved runs and their history.
+// Solves use the current session unless told otherwise.
+type session struct {
+	Name string
+	dir  string
+}
+
+// Entry of session history, one per solve
+type historyEntry struct {
+	Time   time.Time `json:"time"`
+	Input  string    `json:"input"`
+	Seed   int64     `json:"seed"`
+	Value  int       `json:"value"`
+	Weight float64   `json:"weight"`
+	Locked []string  `json:"locked,omitempty"`
+	// Stored run with the solution, relative to session directory
+	Run string `json:"run,omitempty"`
+}
+
+// Checking that name can be used as directory name
+func checkSessionName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+	return nil
+}
+
+func openSession(name string) (*session, error) {
+	if err := checkSessionName(name); err != nil {
+		return nil, err
+	}
+	s := &session{Name: name, dir: filepath.Join(sessionRoot, "sessions", name)}
+	if _, err := os.Stat(s.problemFile()); err != nil {
+		return nil, fmt.Errorf("session %s not found", name)
+	}
+	return s, nil
+}
+
+// Current session, nil if there is none
+func currentSession() (*session, error) {
+	data, err := os.ReadFile(filepath.Join(sessionRoot, "current"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openSession(strings.TrimSpace(string(data)))
+}
+
+// Making session current, empty name leaves no session current
+func setCurrentSession(name string) error {
+	filename := filepath.Join(sessionRoot, "current")
+	if name == "" {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(filename, []byte(name+"\n"), 0644)
+}
+
+// Creating session with its own copy of the problem, so later changes of the input file don't affect it.
+// The copy is TOML, the only format keeping params of the instance.
+func startSession(name string, instance *Instance) (*session, error) {
+	if err := checkSessionName(name); err != nil {
+		return nil, err
+	}
+	if _, err := openSession(name); err == nil {
+		return nil, fmt.Errorf("session %s already exists", name)
+	}
+	s := &session{Name: name, dir: filepath.Join(sessionRoot, "sessions", name)}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := writeInstanceFile(s.problemFile(), instance, "toml", ','); err != nil {
+		return nil, err
+	}
+	return s, setCurrentSession(name)
+}
+
+func (s *session) problemFile() string {
+	return filepath.Join(s.dir, "problem.toml")
+}
+
+// Directory of stored runs, used as run store of session solves
+func (s *session) runsDir() string {
+	return filepath.Join(s.dir, "runs")
+}
+
+// Names of locked items, every solve in session has to include them
+func (s *session) locks() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, "locks.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("locks of session %s: %v", s.Name, err)
+	}
+	return names, nil
+}
+
+func (s *session) setLocks(names []string) error {
+	sort.Strings(names)
+	data, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, "locks.json"), data, 0644)
+}
+
+// Appending entry to session history
+func (s *session) record(entry historyEntry) error {
+	file, err := os.OpenFile(filepath.Join(s.dir, "history.ndjson"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = file.Write(append(data, '\n'))
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Reading session history, oldest entry first
+func (s *session) history() ([]historyEntry, error) {
+	file, err := os.Open(filepath.Join(s.dir, "history.ndjson"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("history of session %s: %v", s.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Indices of locked items by their names, every copy of a name is locked like with -require.
+// Names of no item are returned separately.
+func lockedIndices(items []Item, names []string) (locked []int, unknown []string) {
+	named := map[string]bool{}
+	for _, name := range names {
+		named[name] = true
+	}
+	matched := map[string]bool{}
+	for i, item := range items {
+		if named[item.Name] {
+			locked = append(locked, i)
+			matched[item.Name] = true
+		}
+	}
+	for _, name := range names {
+		if !matched[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return locked, unknown
+}
+
+// Running session subcommands: start, use, end, status, lock, unlock and history
+func runSession(args []string) {
+	if len(args) == 0 {
+		invalid("Usage: session start|use|end|status|lock|unlock|history [flags] [args]")
+	}
+	command, args := args[0], args[1:]
+	fs := flag.NewFlagSet("session "+command, flag.ExitOnError)
+	var input *inputFlags
+	if command == "start" {
+		input = addInputFlags(fs)
+	}
+	logging := addLogFlags(fs)
+	// Flags may come after names too, as in: session start trip -input items.json
+	positional := parseInterspersed(fs, args)
+	logging.apply()
+
+	// Commands besides start, use and end work on the current session
+	var s *session
+	var err error
+	switch command {
+	case "start", "use":
+		if len(positional) != 1 {
+			invalid("Usage: session " + command + " [flags] name")
+		}
+	case "end":
+	case "status", "lock", "unlock", "history":
+		if s, err = currentSession(); err != nil {
+			fatal("Error while opening the current session", "err", err)
+		}
+		if s == nil {
+			invalid("No current session, start one with: session start name")
+		}
+	default:
+		invalid("Unknown session command, expected start, use, end, status, lock, unlock or history", "command", command)
+	}
+
+	switch command {
+	case "start":
+		instance, err := input.read()
+		if err != nil {
+			invalid("Error while reading the file", "err", err)
+		}
+		if _, err := startSession(positional[0], instance); err != nil {
+			fatal("Error while starting session", "err", err)
+		}
+		fmt.Printf("Started session %s with %d items from %s\n", positional[0], len(instance.Items), *input.file)
+	case "use":
+		if _, err := openSession(positional[0]); err != nil {
+			invalid("Error while opening session", "err", err)
+		}
+		if err := setCurrentSession(positional[0]); err != nil {
+			fatal("Error while switching session", "err", err)
+		}
+		fmt.Printf("Using session %s\n", positional[0])
+	case "end":
+		if err := setCurrentSession(""); err != nil {
+			fatal("Error while ending session", "err", err)
+		}
+		fmt.Println("No session is current, stored sessions are kept")
+	case "lock", "unlock":
+		names, err := s.locks()
+		if err != nil {
+			fatal("Error while reading locks", "err", err)
+		}
+		instance, err := readInstance(s.problemFile(), InputOptions{})
+		if err != nil {
+			fatal("Error while reading session problem", "err", err)
+		}
+		if _, unknown := lockedIndices(instance.Items, positional); len(unknown) > 0 && command == "lock" {
+			invalid("Items not found in session problem", "names", strings.Join(unknown, ","))
+		}
+		set := map[string]bool{}
+		for _, name := range names {
+			set[name] = true
+		}
+		for _, name := range positional {
+			set[name] = command == "lock"
+		}
+		names = names[:0]
+		for name, locked := range set {
+			if locked {
+				names = append(names, name)
+			}
+		}
+		if err := s.setLocks(names); err != nil {
+			fatal("Error while writing locks", "err", err)
+		}
+		fmt.Printf("Locked items: %s\n", strings.Join(names, ", "))
+	case "status":
+		names, err := s.locks()
+		if err != nil {
+			fatal("Error while reading locks", "err", err)
+		}
+		history, err := s.history()
+		if err != nil {
+			fatal("Error while reading history", "err", err)
+		}
+		fmt.Printf("Session: %s\nProblem: %s\nLocked items: %s\nSolves: %d\n",
+			s.Name, s.problemFile(), strings.Join(names, ", "), len(history))
+		if len(history) > 0 {
+			last := history[len(history)-1]
+			fmt.Printf("Last solve: %s, value %d, weight %s\n", last.Time.Format(time.RFC3339), last.Value, formatFloat(last.Weight))
+		}
+	case "history":
+		history, err := s.history()
+		if err != nil {
+			fatal("Error while reading history", "err", err)
+		}
+		for n, entry := range history {
+			fmt.Printf("%d. %s value %d, weight %s, seed %d, input %s", n+1, entry.Time.Format(time.RFC3339),
+				entry.Value, formatFloat(entry.Weight), entry.Seed, entry.Input)
+			if len(entry.Locked) > 0 {
+				fmt.Printf(", locked %s", strings.Join(entry.Locked, ", "))
+			}
+			fmt.Println()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Running test in a temporary working directory, sessions live in the working directory
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// Session keeps its own copy of the problem with params, locks and history, and stays current until ended
+func TestSession(t *testing.T) {
+	inTempDir(t)
+	instance := &Instance{
+		Capacity: 4,
+		Items:    []Item{{Name: "tent", Weight: 2, Value: 30, Quantity: 2}, {Name: "map", Weight: 0.25, Value: 4}},
+		Params:   map[string]interface{}{"seed": int64(7)},
+	}
+	s, err := startSession("trip", instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := startSession("trip", instance); err == nil {
+		t.Error("session started twice")
+	}
+	current, err := currentSession()
+	if err != nil || current == nil || current.Name != "trip" {
+		t.Fatalf("current session %v, error %v", current, err)
+	}
+	problem, err := readInstance(current.problemFile(), InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(problem.Items, instance.Items) || problem.Params["seed"] != int64(7) {
+		t.Errorf("problem %+v, want %+v", problem, instance)
+	}
+
+	if err := s.setLocks([]string{"tent", "map"}); err != nil {
+		t.Fatal(err)
+	}
+	locks, err := s.locks()
+	if err != nil || !reflect.DeepEqual(locks, []string{"map", "tent"}) {
+		t.Errorf("locks %v, error %v", locks, err)
+	}
+	_, items := expandQuantities(problem.Items)
+	locked, unknown := lockedIndices(items, append(locks, "stove"))
+	if !reflect.DeepEqual(locked, []int{0, 1, 2}) || !reflect.DeepEqual(unknown, []string{"stove"}) {
+		t.Errorf("locked %v, unknown %v", locked, unknown)
+	}
+
+	entry := historyEntry{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Seed: 7, Value: 64, Weight: 4.25, Locked: locks}
+	for range 2 {
+		if err := s.record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := s.history()
+	if err != nil || len(history) != 2 || !reflect.DeepEqual(history[1], entry) {
+		t.Errorf("history %+v, error %v", history, err)
+	}
+
+	if err := setCurrentSession(""); err != nil {
+		t.Fatal(err)
+	}
+	if current, err := currentSession(); current != nil || err != nil {
+		t.Errorf("current session %v after end, error %v", current, err)
+	}
+	if _, err := openSession("trip"); err != nil {
+		t.Errorf("ended session is gone: %v", err)
+	}
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := startSession(name, instance); err == nil {
+			t.Errorf("session %q started", name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Solution saved for warm-starting later runs. Selected items are kept by name too,
+// so that it still applies when items are added, removed or reordered.
+type savedSolution struct {
+	Input    string   `json:"input"`
+	Capacity float64  `json:"capacity"`
+	Value    int      `json:"value"`
+	Weight   float64  `json:"weight"`
+	Items    []string `json:"items"`
+	Solution []int    `json:"solution"`
+}
+
+func newSavedSolution(inputFile string, capacity float64, result Result, items []Item) savedSolution {
+	saved := savedSolution{Input: inputFile, Capacity: capacity, Value: result.Value, Weight: result.Weight,
+		Items: []string{}, Solution: result.Solution}
+	for i, included := range result.Solution {
+		if included == 1 {
+			saved.Items = append(saved.Items, items[i].Name)
+		}
+	}
+	return saved
+}
+
+// Writing solution as indented JSON
+func writeSolution(filename string, saved savedSolution) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// Reading solution to warm-start from: saved solution, run manifest or run of the run store
+func readSolution(filename string) (*savedSolution, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	// Items of stored runs are the whole instance, so manifests are told apart first
+	var run struct {
+		Result   *ManifestResult `json:"result"`
+		Manifest *Manifest       `json:"manifest"`
+		Input    string          `json:"input"`
+		Params   Params          `json:"params"`
+		Capacity float64         `json:"capacity"`
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if run.Manifest != nil {
+		run.Result, run.Input, run.Params = &run.Manifest.Result, run.Manifest.Input, run.Manifest.Params
+	}
+	saved := &savedSolution{}
+	if run.Result != nil {
+		capacity := run.Capacity
+		if capacity == 0 {
+			capacity = run.Params.MaxWeight
+		}
+		saved = &savedSolution{Input: run.Input, Capacity: capacity, Value: run.Result.Value, Weight: run.Result.Weight,
+			Items: run.Result.Items, Solution: run.Result.Solution}
+	} else if err := json.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if saved.Items == nil && saved.Solution == nil {
+		return nil, fmt.Errorf("%s: no solution found", filename)
+	}
+	return saved, nil
+}
+
+// Mapping saved solution onto items: selected items are matched by name, or by position when
+// the solution has no names. Names of no item are returned separately.
+func (s *savedSolution) apply(items []Item) (solution []int, unknown []string, err error) {
+	solution = make([]int, len(items))
+	if s.Items == nil {
+		if len(s.Solution) != len(items) {
+			return nil, nil, fmt.Errorf("solution has %d entries for %d items and no item names", len(s.Solution), len(items))
+		}
+		copy(solution, s.Solution)
+		return solution, nil, nil
+	}
+
+	// Duplicate names take items of that name one by one
+	positions := map[string][]int{}
+	for i, item := range items {
+		positions[item.Name] = append(positions[item.Name], i)
+	}
+	for _, name := range s.Items {
+		if free := positions[name]; len(free) > 0 {
+			solution[free[0]] = 1
+			positions[name] = free[1:]
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return solution, unknown, nil
+}
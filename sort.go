@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Order of printed and exported items: by value, weight, density or name, input order if empty
+type itemOrder struct {
+	by   string
+	desc bool
+}
+
+// Parsing order given as field with optional :asc or :desc, numbers are sorted
+// in descending order and names in ascending order by default
+func parseItemOrder(spec string) (itemOrder, error) {
+	if spec == "" {
+		return itemOrder{}, nil
+	}
+	by, direction, _ := strings.Cut(spec, ":")
+	order := itemOrder{by: by, desc: by != "name"}
+	switch by {
+	case "value", "weight", "density", "name":
+	default:
+		return itemOrder{}, fmt.Errorf("unknown sort field %q, expected value, weight, density or name", by)
+	}
+	switch direction {
+	case "":
+	case "asc":
+		order.desc = false
+	case "desc":
+		order.desc = true
+	default:
+		return itemOrder{}, fmt.Errorf("unknown sort direction %q, expected asc or desc", direction)
+	}
+	return order, nil
+}
+
+// Indices of items included in solution in this order, ties keep input order.
+// Solutions of the unbounded problem count copies, items taken any number of times are included.
+func (o itemOrder) selected(solution []int, items []Item) []int {
+	var indices []int
+	for i, included := range solution {
+		if included > 0 {
+			indices = append(indices, i)
+		}
+	}
+	less := func(a, b Item) bool {
+		switch o.by {
+		case "value":
+			return a.Value < b.Value
+		case "weight":
+			return a.Weight < b.Weight
+		case "density":
+			return density(a) < density(b)
+		}
+		return a.Name < b.Name
+	}
+	if o.by != "" {
+		sort.SliceStable(indices, func(i, j int) bool {
+			a, b := items[indices[i]], items[indices[j]]
+			if o.desc {
+				return less(b, a)
+			}
+			return less(a, b)
+		})
+	}
+	return indices
+}
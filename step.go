@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"knapsack/knapsacktest"
+)
+
+// State of one iteration of simulated annealing
+type Step struct {
+	Iteration int
+	// Temperature the candidate was judged at
+	Temp            float64
+	Candidate       []int
+	CandidateValue  int
+	CandidateWeight float64
+	// Value of current solution before the move
+	CurrentValue int
+	// Candidate fits into the knapsack and satisfies hard constraints
+	Feasible bool
+	// Soft constraint violation of the candidate
+	Violation float64
+	// Probability of accepting the candidate, zero for infeasible ones
+	Probability float64
+	Accepted    bool
+	// Best value found so far, including this candidate, 0 while soft constraints are unsatisfied
+	BestValue int
+	// Weight of the best solution, 0 while soft constraints are unsatisfied
+	BestWeight float64
+}
+
+// Value change of the move
+func (s Step) Delta() int {
+	return s.CandidateValue - s.CurrentValue
+}
+
+// Creating step hook printing every iteration, with optional pause after each one
+func stepPrinter(w io.Writer, delay time.Duration) func(Step) {
+	return func(s Step) {
+		decision := "rejected"
+		switch {
+		case !s.Feasible:
+			decision = "overweight"
+		case s.Accepted:
+			decision = "accepted"
+		}
+		if s.Violation > 0 {
+			decision += fmt.Sprintf(" violation=%.4f", s.Violation)
+		}
+		fmt.Fprintf(w, "#%d T=%.4f candidate=%v value=%d weight=%.3f delta=%+d p=%.4f %s\n",
+			s.Iteration, s.Temp, s.Candidate, s.CandidateValue, s.CandidateWeight, s.Delta(), s.Probability, decision)
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+}
+
+// Combining several step hooks into one, nil hooks are skipped
+func chainSteps(hooks ...func(Step)) func(Step) {
+	var active []func(Step)
+	for _, hook := range hooks {
+		if hook != nil {
+			active = append(active, hook)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	return func(s Step) {
+		for _, hook := range active {
+			hook(s)
+		}
+	}
+}
+
+// Creating step hook feeding run trace into invariant checker
+func traceChecker(checker *knapsacktest.Checker) func(Step) {
+	return func(s Step) {
+		checker.Observe(knapsacktest.Event{
+			Iteration:   s.Iteration,
+			Temp:        s.Temp,
+			Probability: s.Probability,
+			BestValue:   s.BestValue,
+		})
+	}
+}
+
+// Creating step hook printing a progress line every interval of time or every n iterations,
+// whichever comes first, zero disables either of them
+func progressPrinter(w io.Writer, interval time.Duration, n int) func(Step) {
+	start := time.Now()
+	printed := start
+	return func(s Step) {
+		due := n > 0 && s.Iteration%n == 0
+		// Checking the clock is too slow for every iteration
+		if !due && interval > 0 && s.Iteration%256 == 0 {
+			due = time.Since(printed) >= interval
+		}
+		if !due {
+			return
+		}
+		printed = time.Now()
+		fmt.Fprintf(w, "[%v] iteration %d, temperature %.4f, best value %d, best weight %.4f\n",
+			printed.Sub(start).Round(time.Millisecond), s.Iteration, s.Temp, s.BestValue, s.BestWeight)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"knapsack/knapsacktest"
+)
+
+// Real annealing runs keep the invariants the trace checker asserts, whatever the epoch length
+func TestAnnealingTrace(t *testing.T) {
+	items := []Item{
+		{Name: "tent", Weight: 2, Value: 30},
+		{Name: "stove", Weight: 1, Value: 12},
+		{Name: "map", Weight: 0.25, Value: 4},
+		{Name: "rope", Weight: 0.5, Value: 5},
+		{Name: "lamp", Weight: 0.75, Value: 9},
+	}
+	for _, epochLength := range []int{1, 7} {
+		checker := &knapsacktest.Checker{EpochLength: epochLength}
+		steps := 0
+		params := Params{MaxWeight: 3, MaxTemp: 100, MinTemp: 0.1, CoolingRate: 0.9, EpochLength: epochLength, Seed: 1,
+			OnStep: chainSteps(traceChecker(checker), func(Step) { steps++ })}
+		result := simulatedAnnealing(context.Background(), items, params)
+		if err := checker.Err(); err != nil {
+			t.Errorf("epoch length %d: %v", epochLength, err)
+		}
+		if steps == 0 || steps != result.Iterations {
+			t.Errorf("epoch length %d: %d steps checked of %d iterations", epochLength, steps, result.Iterations)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Subset-sum problem as knapsack: every item is worth its weight, so the best selection
+// within the target comes closest to it from below. Weights are whole numbers at the scale
+// of their decimal places, or rounded to 4 decimal places, so that sums are exact.
+func subsetSumItems(items []Item) ([]Item, float64) {
+	scale, ok := weightScale(items)
+	if !ok {
+		scale = 1e4
+		slog.Warn("Weights have more than 4 decimal places, they are rounded for the solver")
+	}
+	sums := make([]Item, len(items))
+	for i, item := range items {
+		units := int(math.Round(item.Weight * scale))
+		sums[i] = Item{Name: item.Name, Weight: float64(units), Value: units}
+	}
+	return sums, scale
+}
+
+// Print selected items and how far their sum falls short of the target. Sums are
+// whole numbers of weight units at scale, printed with the decimals of weights and target.
+func showSubsetSum(w io.Writer, solution []int, items, sums []Item, target, scale float64) {
+	decimals := int(math.Round(math.Log10(scale)))
+	if targetScale, ok := weightScale([]Item{{Weight: target}}); ok && targetScale > scale {
+		decimals = int(math.Round(math.Log10(targetScale)))
+	}
+	weight := func(w float64) string {
+		return strconv.FormatFloat(w, 'f', decimals, 64)
+	}
+
+	fmt.Fprintln(w, "Items summing up to the target:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, " ITEM\tWEIGHT")
+	count, units := 0, 0
+	for i, included := range solution {
+		if included == 1 {
+			count++
+			units += sums[i].Value
+			fmt.Fprintf(tw, " %s\t%s\n", items[i].Name, weight(items[i].Weight))
+		}
+	}
+	sum := float64(units) / scale
+	fmt.Fprintf(tw, " Total (%d items)\t%s\n", count, weight(sum))
+	fmt.Fprintf(tw, " Target\t%s\n", weight(target))
+	tw.Flush()
+	if gap := target - sum; math.Abs(gap*scale) > 1e-6 {
+		fmt.Fprintf(w, "Short of the target by %s\n", weight(gap))
+	} else {
+		fmt.Fprintln(w, "Target hit exactly")
+	}
+}
+
+// Subset-sum subcommand: finding items whose weights sum up to the target exactly,
+// or as close as possible from below
+func runSubsetSum(args []string) {
+	fs := flag.NewFlagSet("subset-sum", flag.ExitOnError)
+	input := addInputFlags(fs)
+	target := fs.Float64("target", 0, "sum of weights to hit, capacity of the input if not given")
+	solverName := fs.String("solver", "auto", "algorithm: auto, exact when fast enough and annealing otherwise, annealing, exhaustive or dp")
+	maxTemp := fs.Float64("max-temp", 1000.0, "initial temperature")
+	minTemp := fs.Float64("min-temp", 0.1, "temperature to stop at")
+	coolingRate := fs.Float64("cooling-rate", 0.95, "temperature multiplier applied after every epoch")
+	epochLength := fs.Int("epoch-length", 100, "iterations per temperature before cooling down")
+	seed := fs.Int64("seed", 0, "seed of random source for reproducible runs, 0 seeds from the clock")
+	timeLimit := fs.Duration("time-limit", 0, "time budget of the solve, unlimited if zero")
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	if instance.Capacity > 0 {
+		if err := applyConfig(fs, map[string]interface{}{"target": instance.Capacity}); err != nil {
+			invalid("Error in input capacity", "err", err)
+		}
+	}
+	if *target <= 0 {
+		invalid("-target must be positive, the input gives no capacity", "target", *target)
+	}
+	// Refusing to solve nonsense input like solve does, the target is the capacity
+	problems := validateInstance(instance, *target)
+	for _, p := range problems {
+		p.log()
+	}
+	if hasErrors(problems) {
+		invalid("Invalid input", "problems", len(problems))
+	}
+	unsupported, err := extraConstraints(instance)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	for _, item := range instance.Items {
+		if item.Required || item.Excluded {
+			unsupported = append(unsupported, "required and excluded items")
+			break
+		}
+	}
+	if len(unsupported) > 0 {
+		invalid("Subset-sum problem can't take constraints", "constraints", strings.Join(unsupported, ","))
+	}
+	_, items := expandQuantities(instance.Items)
+	sums, scale := subsetSumItems(items)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	// Capacity is the target in weight units, annealing stops as soon as it hits it
+	capacity := math.Floor(*target*scale + 1e-6)
+	params := Params{MaxWeight: capacity, MaxTemp: *maxTemp, MinTemp: *minTemp, CoolingRate: *coolingRate,
+		EpochLength: *epochLength, Init: "greedy", Seed: *seed, Target: int(capacity)}
+	var solver Solver
+	switch *solverName {
+	case "auto":
+		if solver, _, err = exactSolver(sums, params); err != nil {
+			solver = simulatedAnnealing
+		}
+	case "annealing":
+		solver = simulatedAnnealing
+	case "exhaustive":
+		if len(sums) > maxExhaustiveItems {
+			invalid("Instance too large for exhaustive solver", "max", maxExhaustiveItems, "items", len(sums))
+		}
+		solver = exhaustiveSolver
+	case "dp":
+		if err := dpApplicable(sums, params); err != nil {
+			invalid("Dynamic programming solver can't solve the instance", "err", err)
+		}
+		solver = dpSolver
+	default:
+		invalid("Unknown solver, expected auto, annealing, exhaustive or dp", "solver", *solverName)
+	}
+
+	ctx := context.Background()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+	start := time.Now()
+	result := solver(ctx, sums, params)
+
+	fmt.Printf("Seed: %d\n", result.Seed)
+	showSubsetSum(os.Stdout, result.Solution, items, sums, *target, scale)
+	fmt.Printf("Execution time: %v\n", time.Since(start))
+}
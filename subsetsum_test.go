@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// Weights summing up to the target in decimals hit it exactly in weight units, with every solver
+func TestSubsetSumExact(t *testing.T) {
+	items := []Item{{Name: "a", Weight: 0.1}, {Name: "b", Weight: 0.2}, {Name: "c", Weight: 0.25}}
+	sums, scale := subsetSumItems(items)
+	if scale != 100 || sums[0].Value != 10 || sums[2].Weight != 25 {
+		t.Fatalf("scale %v, items %+v", scale, sums)
+	}
+	params := Params{MaxWeight: 30, MaxTemp: 100, MinTemp: 0.1, CoolingRate: 0.95, EpochLength: 10,
+		Init: "greedy", Seed: 1, Target: 30}
+	for name, solver := range map[string]Solver{"annealing": simulatedAnnealing, "exhaustive": exhaustiveSolver, "dp": dpSolver} {
+		result := solver(context.Background(), sums, params)
+		if result.Value != 30 {
+			t.Errorf("%s: sum %d, want 30", name, result.Value)
+		}
+		var out bytes.Buffer
+		showSubsetSum(&out, result.Solution, items, sums, 0.3, scale)
+		if !strings.Contains(out.String(), "Total (2 items)  0.30") || !strings.Contains(out.String(), "Target hit exactly") {
+			t.Errorf("%s: report\n%s", name, out.String())
+		}
+	}
+}
+
+// Target with more decimals than weights is missed by the right amount, not by rounding noise
+func TestSubsetSumShort(t *testing.T) {
+	items := []Item{{Name: "a", Weight: 0.1}, {Name: "b", Weight: 0.2}}
+	sums, scale := subsetSumItems(items)
+	var out bytes.Buffer
+	showSubsetSum(&out, []int{1, 1}, items, sums, 0.33, scale)
+	if !strings.Contains(out.String(), "Short of the target by 0.03\n") {
+		t.Errorf("report\n%s", out.String())
+	}
+}
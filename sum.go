@@ -0,0 +1,42 @@
+package main
+
+// Compensated (Neumaier) summation of floats, keeps rounding errors of
+// long sums of small weights from drifting across the capacity boundary
+type neumaierSum struct {
+	sum float64
+	// Running compensation of lost low-order bits
+	c float64
+}
+
+func (s *neumaierSum) add(x float64) {
+	t := s.sum + x
+	if abs(s.sum) >= abs(x) {
+		s.c += (s.sum - t) + x
+	} else {
+		s.c += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+func (s *neumaierSum) value() float64 {
+	return s.sum + s.c
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Calculating total value and compensated total weight of given solution
+func computeEnergyCompensated(solution []int, items []Item) (totalValue int, totalWeight float64) {
+	var weight neumaierSum
+	for i, included := range solution {
+		if included == 1 {
+			totalValue += items[i].Value
+			weight.add(items[i].Weight)
+		}
+	}
+	return totalValue, weight.value()
+}
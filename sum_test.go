@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Ten thousand items of weight 0.1 filling the capacity of 1000 exactly
+func fractionalItems() ([]Item, []int) {
+	items := make([]Item, 10000)
+	solution := make([]int, len(items))
+	for i := range items {
+		items[i] = Item{Name: "item", Weight: 0.1, Value: 1}
+		solution[i] = 1
+	}
+	return items, solution
+}
+
+func TestCompensatedSumAtCapacity(t *testing.T) {
+	items, solution := fractionalItems()
+	value, weight := computeEnergyCompensated(solution, items)
+	if value != len(items) || weight != 1000 {
+		t.Errorf("compensated totals are %d and %v, want %d and 1000", value, weight, len(items))
+	}
+	// Naive sum drifts over the capacity, which is what -kahan is for
+	if _, naive := computeEnergy(solution, items); naive <= 1000 {
+		t.Errorf("naive sum is %v, expected it to drift over 1000", naive)
+	}
+}
+
+func TestEvaluatorAtCapacity(t *testing.T) {
+	items, solution := fractionalItems()
+	for _, compensated := range []bool{false, true} {
+		eval := newEvaluator(items, Params{MaxWeight: 1000, CompensatedSum: compensated})
+		// Reaching the full solution from a different one, so that totals are updated incrementally
+		partial := make([]int, len(items))
+		for i := 0; i < len(items); i += 3 {
+			partial[i] = 1
+		}
+		eval.evaluate(partial)
+		_, got := eval.evaluate(solution)
+
+		var want float64
+		if compensated {
+			_, want = computeEnergyCompensated(solution, items)
+		} else {
+			_, want = computeEnergy(solution, items)
+		}
+		if got != want {
+			t.Errorf("compensated %v: evaluated weight %v, full evaluation gives %v", compensated, got, want)
+		}
+		if fits := got <= 1000; fits != compensated {
+			t.Errorf("compensated %v: weight %v fits %v", compensated, got, fits)
+		}
+	}
+}
+
+func TestEvaluatorIncremental(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	items := make([]Item, 200)
+	for i := range items {
+		items[i] = Item{Name: "item", Weight: r.Float64() * 10, Value: r.Intn(100)}
+	}
+	eval := newEvaluator(items, Params{MaxWeight: 500})
+	solution := make([]int, len(items))
+	for step := 0; step < 10000; step++ {
+		// Flipping a few items like the annealing neighbourhood does
+		for k := r.Intn(3); k >= 0; k-- {
+			i := r.Intn(len(items))
+			solution[i] = 1 - solution[i]
+		}
+		value, weight := eval.evaluate(solution)
+		wantValue, wantWeight := computeEnergyCompensated(solution, items)
+		if value != wantValue || abs(weight-wantWeight) > 1e-9 {
+			t.Fatalf("step %d: evaluated %d and %v, full evaluation gives %d and %v",
+				step, value, weight, wantValue, wantWeight)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Most capacities a sweep solves for
+const maxSweepSteps = 10000
+
+// Best solution for one capacity of a sweep
+type sweepPoint struct {
+	Capacity float64
+	Result   Result
+}
+
+// Parsing sweep like 1:20:0.5 into capacities from 1 to 20 by 0.5, the last one included
+func parseCapacitySweep(spec string) ([]float64, error) {
+	fields := strings.Split(spec, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("capacity sweep must be from:to:step, got %q", spec)
+	}
+	var bounds [3]float64
+	for i, field := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || x < 0 || math.IsInf(x, 0) {
+			return nil, fmt.Errorf("capacity sweep must be from:to:step of non-negative numbers, got %q", spec)
+		}
+		bounds[i] = x
+	}
+	from, to, step := bounds[0], bounds[1], bounds[2]
+	if step <= 0 || to < from {
+		return nil, fmt.Errorf("capacity sweep needs positive step and from not above to, got %q", spec)
+	}
+	// Capacities are computed from their index, so steps don't add up rounding errors
+	n := int(math.Floor((to-from)/step+1e-9)) + 1
+	if n > maxSweepSteps {
+		return nil, fmt.Errorf("capacity sweep has %d steps, at most %d are allowed", n, maxSweepSteps)
+	}
+	capacities := make([]float64, n)
+	for i := range capacities {
+		capacities[i] = from + float64(i)*step
+	}
+	return capacities, nil
+}
+
+// Solving items for every capacity independently
+func solveSweep(ctx context.Context, o *Orchestrator, items []Item, params Params, restarts int,
+	capacities []float64, solver Solver) []sweepPoint {
+	points := make([]sweepPoint, len(capacities))
+	for i, capacity := range capacities {
+		config := params
+		config.MaxWeight = capacity
+		points[i] = sweepPoint{Capacity: capacity, Result: o.Restarts(ctx, items, config, restarts, solver)}
+	}
+	return points
+}
+
+// Print value against capacity with the value one more step adds
+func showSweep(w io.Writer, points []sweepPoint, items []Item) {
+	decimals := weightDecimals(items)
+	fmt.Fprintln(w, "Value by capacity:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, " CAPACITY\tVALUE\tWEIGHT\tITEMS\tGAIN")
+	for i, point := range points {
+		gain := "-"
+		if i > 0 {
+			gain = fmt.Sprintf("%+d", point.Result.Value-points[i-1].Result.Value)
+		}
+		fmt.Fprintf(tw, " %s\t%d\t%.*f\t%d\t%s\n", formatFloat(point.Capacity), point.Result.Value,
+			decimals, point.Result.Weight, countSelected(point.Result.Solution), gain)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "-------------------------------------------------------------")
+}
+
+// Writing value against capacity as CSV for spreadsheets and plotting
+func writeSweepCSV(w io.Writer, points []sweepPoint, items []Item, delimiter rune) error {
+	decimals := weightDecimals(items)
+	writer := csv.NewWriter(w)
+	if delimiter != 0 {
+		writer.Comma = delimiter
+	}
+	writer.Write([]string{"capacity", "value", "weight", "items"})
+	for _, point := range points {
+		writer.Write([]string{formatFloat(point.Capacity), strconv.Itoa(point.Result.Value),
+			strconv.FormatFloat(point.Result.Weight, 'f', decimals, 64), strconv.Itoa(countSelected(point.Result.Solution))})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Number of items included in solution
+func countSelected(solution []int) int {
+	count := 0
+	for _, included := range solution {
+		count += included
+	}
+	return count
+}
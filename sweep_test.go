@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseCapacitySweep(t *testing.T) {
+	capacities, err := parseCapacitySweep("1:2:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 1.5, 2}; !reflect.DeepEqual(capacities, want) {
+		t.Errorf("capacities %v, want %v", capacities, want)
+	}
+	for _, spec := range []string{"1:2", "2:1:0.5", "1:2:0", "a:2:1"} {
+		if _, err := parseCapacitySweep(spec); err == nil {
+			t.Errorf("%s accepted", spec)
+		}
+	}
+}
+
+func TestWriteSweepCSV(t *testing.T) {
+	items := []Item{{Name: "tent", Weight: 2, Value: 10}, {Name: "stove", Weight: 0.5, Value: 4}}
+	points := []sweepPoint{
+		{Capacity: 1, Result: Result{Solution: []int{0, 1}, Value: 4, Weight: 0.5}},
+		{Capacity: 2.5, Result: Result{Solution: []int{1, 1}, Value: 14, Weight: 2.5}},
+	}
+	var out bytes.Buffer
+	if err := writeSweepCSV(&out, points, items, ';'); err != nil {
+		t.Fatal(err)
+	}
+	if want := "capacity;value;weight;items\n1;4;0.5;1\n2.5;14;2.5;2\n"; out.String() != want {
+		t.Errorf("CSV\n%s\nwant\n%s", out.String(), want)
+	}
+}
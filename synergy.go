@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Bonus value of selecting two complementary items together, like a camera and its lens.
+// Negative bonus makes items worth less together. Items are named, so that any copy counts,
+// and the bonus is added once per pair. Pair of the same name needs two of its copies.
+type Synergy struct {
+	Items []string `json:"items"`
+	Bonus int      `json:"bonus"`
+}
+
+// Total bonus of synergies whose items are all selected
+func synergyBonus(solution []int, items []Item, synergies []Synergy) int {
+	if len(synergies) == 0 {
+		return 0
+	}
+	selected := map[string]int{}
+	for i, included := range solution {
+		if included == 1 {
+			selected[items[i].Name]++
+		}
+	}
+	bonus := 0
+	for _, s := range synergies {
+		if len(s.Items) != 2 {
+			continue
+		}
+		if a, b := s.Items[0], s.Items[1]; a != b && selected[a] > 0 && selected[b] > 0 || a == b && selected[a] > 1 {
+			bonus += s.Bonus
+		}
+	}
+	return bonus
+}
+
+// Synergies resolved to items for incremental evaluation. Names taking part in synergies
+// get ids, and every flip adds or removes the bonuses of its name's partners.
+type synergyIndex struct {
+	// Name id of every item, -1 if its name is in no synergy
+	nameOf   []int
+	partners [][]synergyPartner
+	// Bonus of pairs of the same name, counting once its second item is selected
+	self []int
+	// Number of selected items of every name
+	selected []int
+	bonus    int
+}
+
+type synergyPartner struct {
+	name  int
+	bonus int
+}
+
+func newSynergyIndex(items []Item, synergies []Synergy) *synergyIndex {
+	ids := map[string]int{}
+	id := func(name string) int {
+		if _, ok := ids[name]; !ok {
+			ids[name] = len(ids)
+		}
+		return ids[name]
+	}
+	var pairs [][3]int
+	for _, s := range synergies {
+		if len(s.Items) == 2 {
+			pairs = append(pairs, [3]int{id(s.Items[0]), id(s.Items[1]), s.Bonus})
+		}
+	}
+
+	index := &synergyIndex{nameOf: make([]int, len(items)), partners: make([][]synergyPartner, len(ids)),
+		self: make([]int, len(ids)), selected: make([]int, len(ids))}
+	for _, pair := range pairs {
+		a, b := pair[0], pair[1]
+		if a == b {
+			index.self[a] += pair[2]
+			continue
+		}
+		index.partners[a] = append(index.partners[a], synergyPartner{b, pair[2]})
+		index.partners[b] = append(index.partners[b], synergyPartner{a, pair[2]})
+	}
+	for i, item := range items {
+		if n, ok := ids[item.Name]; ok {
+			index.nameOf[i] = n
+		} else {
+			index.nameOf[i] = -1
+		}
+	}
+	return index
+}
+
+// Selecting item, its name's bonuses count once the first item of the name is selected,
+// and bonuses of its pairs with itself once the second one is
+func (s *synergyIndex) add(i int) {
+	n := s.nameOf[i]
+	if n < 0 {
+		return
+	}
+	s.selected[n]++
+	switch s.selected[n] {
+	case 1:
+		s.bonus += s.partnerBonus(n)
+	case 2:
+		s.bonus += s.self[n]
+	}
+}
+
+// Deselecting item, its name's bonuses stop counting with the last selected item of the name
+func (s *synergyIndex) remove(i int) {
+	n := s.nameOf[i]
+	if n < 0 {
+		return
+	}
+	switch s.selected[n] {
+	case 1:
+		s.bonus -= s.partnerBonus(n)
+	case 2:
+		s.bonus -= s.self[n]
+	}
+	s.selected[n]--
+}
+
+// Bonus of synergies of name with selected partners of other names
+func (s *synergyIndex) partnerBonus(n int) int {
+	bonus := 0
+	for _, p := range s.partners[n] {
+		if s.selected[p.name] > 0 {
+			bonus += p.bonus
+		}
+	}
+	return bonus
+}
+
+func (s *synergyIndex) reset() {
+	for n := range s.selected {
+		s.selected[n] = 0
+	}
+	s.bonus = 0
+}
+
+// Sum of positive bonuses, the most synergies can add to any solution
+func maxSynergyBonus(synergies []Synergy) int {
+	bonus := 0
+	for _, s := range synergies {
+		if s.Bonus > 0 {
+			bonus += s.Bonus
+		}
+	}
+	return bonus
+}
+
+// Print bonus of synergies of solution, nothing if instance has none
+func showSynergies(w io.Writer, solution []int, items []Item, synergies []Synergy) {
+	if len(synergies) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Synergy bonus: %d\n", synergyBonus(solution, items, synergies))
+}
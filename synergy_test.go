@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEvaluatorSynergyIncremental(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// Repeated names, like copies of items with quantities
+	names := []string{"camera", "lens", "tripod", "bag", "filter"}
+	items := make([]Item, 40)
+	for i := range items {
+		items[i] = Item{Name: names[r.Intn(len(names))], Weight: r.Float64(), Value: r.Intn(10)}
+	}
+	synergies := []Synergy{
+		{Items: []string{"camera", "lens"}, Bonus: 30},
+		{Items: []string{"camera", "tripod"}, Bonus: 10},
+		{Items: []string{"lens", "filter"}, Bonus: -5},
+		{Items: []string{"bag", "bag"}, Bonus: 7},
+		{Items: []string{"camera", "missing"}, Bonus: 100},
+	}
+	eval := newEvaluator(items, Params{MaxWeight: 100, Synergies: synergies})
+	solution := make([]int, len(items))
+	for step := 0; step < 5000; step++ {
+		for k := r.Intn(3); k >= 0; k-- {
+			i := r.Intn(len(items))
+			solution[i] = 1 - solution[i]
+		}
+		value, _ := eval.evaluate(solution)
+		linear, _ := computeEnergy(solution, items)
+		if want := linear + synergyBonus(solution, items, synergies); value != want {
+			t.Fatalf("step %d: evaluated %d, full evaluation gives %d", step, value, want)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		i := r.Intn(len(items))
+		solution[i] = 1 - solution[i]
+		eval.evaluate(solution)
+	})
+	if allocs > 0 {
+		t.Errorf("evaluation allocates %v times", allocs)
+	}
+}
+
+// Pair of the same name pays its bonus with two copies, not with one
+func TestSynergySameName(t *testing.T) {
+	_, items := expandQuantities([]Item{{Name: "bag", Weight: 1, Value: 2, Quantity: 2}, {Name: "lens", Weight: 1, Value: 3}})
+	synergies := []Synergy{{Items: []string{"bag", "bag"}, Bonus: 7}, {Items: []string{"bag", "lens"}, Bonus: 4}}
+	eval := newEvaluator(items, Params{MaxWeight: 100, Synergies: synergies})
+	tests := []struct {
+		solution []int
+		bonus    int
+	}{
+		{[]int{0, 0, 0}, 0},
+		{[]int{1, 0, 0}, 0},
+		{[]int{0, 1, 0}, 0},
+		{[]int{1, 1, 0}, 7},
+		{[]int{1, 0, 1}, 4},
+		{[]int{1, 1, 1}, 11},
+		{[]int{0, 1, 1}, 4},
+		{[]int{0, 0, 1}, 0},
+	}
+	for _, tt := range tests {
+		if bonus := synergyBonus(tt.solution, items, synergies); bonus != tt.bonus {
+			t.Errorf("%v: bonus %d, want %d", tt.solution, bonus, tt.bonus)
+		}
+		value, _ := eval.evaluate(tt.solution)
+		if linear, _ := computeEnergy(tt.solution, items); value != linear+tt.bonus {
+			t.Errorf("%v: evaluated %d, want %d", tt.solution, value, linear+tt.bonus)
+		}
+	}
+}
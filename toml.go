@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Minimal TOML reader: tables, arrays of tables, dotted keys, strings, numbers, booleans,
+// arrays and inline tables. Multi-line strings and date-time values are not supported.
+
+// Reading instance from TOML: name, capacity and known_optimum top-level keys,
+// items from [[items]] tables and solver params from [params] table
+func readInstanceFromTOML(r io.Reader, strict bool) (*Instance, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoding the same way as JSON input, params are not a part of instance data
+	params, _ := doc["params"].(map[string]interface{})
+	delete(doc, "params")
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := decodeInstanceJSON(encoded, jsonOptions{Strict: strict})
+	if err != nil {
+		return nil, err
+	}
+	instance.Params = params
+	return instance, nil
+}
+
+// Reading solver params from TOML file: keys of [params] table,
+// or top-level keys if there is no such table
+func readParamsFromTOML(filename string) (map[string]interface{}, error) {
+	doc, err := readTOMLFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if table, ok := doc["params"].(map[string]interface{}); ok {
+		return table, nil
+	}
+	params := map[string]interface{}{}
+	for key, value := range doc {
+		// Tables and arrays of tables are data, not params
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// Setting flags from config values, flags already set on command line are kept.
+// Keys may use underscores instead of dashes of flag names.
+func applyConfig(fs *flag.FlagSet, config map[string]interface{}) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for key, value := range config {
+		name := strings.ReplaceAll(key, "_", "-")
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown parameter %q", key)
+		}
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("parameter %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func readTOMLFile(filename string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return doc, nil
+}
+
+// Parsing TOML document into nested maps
+func parseTOML(data string) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	current := doc
+	lines := strings.Split(data, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		num := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+
+		// Array of tables header: [[name]]
+		if strings.HasPrefix(line, "[[") {
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: invalid table header", num)
+			}
+			keys, err := splitTOMLKey(line[2 : len(line)-2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			parent, err := tomlTable(doc, keys[:len(keys)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			last := keys[len(keys)-1]
+			array, _ := parent[last].([]interface{})
+			if _, exists := parent[last]; exists && array == nil {
+				return nil, fmt.Errorf("line %d: %q is not an array of tables", num, last)
+			}
+			current = map[string]interface{}{}
+			parent[last] = append(array, current)
+			continue
+		}
+
+		// Table header: [name]
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header", num)
+			}
+			keys, err := splitTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			current, err = tomlTable(doc, keys)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			continue
+		}
+
+		// Key/value pair, arrays may continue on the following lines
+		eq := indexOutsideQuotes(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", num)
+		}
+		keys, err := splitTOMLKey(line[:eq])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		text := strings.TrimSpace(line[eq+1:])
+		for strings.HasPrefix(text, "[") && !tomlBalanced(text) && i+1 < len(lines) {
+			i++
+			text += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+
+		p := &tomlValue{text: text}
+		value, err := p.parse()
+		if err == nil && strings.TrimSpace(p.text[p.pos:]) != "" {
+			err = fmt.Errorf("unexpected %q after value", strings.TrimSpace(p.text[p.pos:]))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+
+		table, err := tomlTable(current, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		last := keys[len(keys)-1]
+		if _, exists := table[last]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", num, last)
+		}
+		table[last] = value
+	}
+	return doc, nil
+}
+
+// Finding or creating nested table by key path. Path through an array of tables
+// leads to its last table.
+func tomlTable(root map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	table := root
+	for _, key := range keys {
+		switch next := table[key].(type) {
+		case nil:
+			created := map[string]interface{}{}
+			table[key] = created
+			table = created
+		case map[string]interface{}:
+			table = next
+		case []interface{}:
+			last, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not a table", key)
+			}
+			table = last
+		default:
+			return nil, fmt.Errorf("%q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+// Splitting dotted key into parts, parts may be quoted
+func splitTOMLKey(text string) ([]string, error) {
+	var keys []string
+	for _, part := range splitOutsideQuotes(text, '.') {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("empty key in %q", text)
+		case strings.HasPrefix(part, "\""):
+			unquoted, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %s", part)
+			}
+			part = unquoted
+		case strings.HasPrefix(part, "'"):
+			part = strings.Trim(part, "'")
+		}
+		keys = append(keys, part)
+	}
+	return keys, nil
+}
+
+// Index of the first sep outside quotes, so that quoted keys may contain it, -1 if there is none
+func indexOutsideQuotes(text string, sep byte) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			return i
+		}
+	}
+	return -1
+}
+
+func splitOutsideQuotes(text string, sep byte) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// Cutting comment starting with "#" outside quotes off the line
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// Checking if all brackets outside quotes are closed
+func tomlBalanced(text string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// Parser of TOML values
+type tomlValue struct {
+	text string
+	pos  int
+}
+
+func (p *tomlValue) skipSpaces() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlValue) parse() (interface{}, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch c := p.text[p.pos]; c {
+	case '"', '\'':
+		end := p.pos + 1
+		for end < len(p.text) && p.text[end] != c {
+			if c == '"' && p.text[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.text) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		raw := p.text[p.pos : end+1]
+		p.pos = end + 1
+		if c == '\'' {
+			return raw[1 : len(raw)-1], nil
+		}
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+
+	case '[':
+		p.pos++
+		array := []interface{}{}
+		for {
+			p.skipSpaces()
+			if p.pos < len(p.text) && p.text[p.pos] == ']' {
+				p.pos++
+				return array, nil
+			}
+			value, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+			p.skipSpaces()
+			if p.pos < len(p.text) && p.text[p.pos] == ',' {
+				p.pos++
+			} else if p.pos >= len(p.text) || p.text[p.pos] != ']' {
+				return nil, fmt.Errorf("expected \",\" or \"]\" in array")
+			}
+		}
+
+	case '{':
+		p.pos++
+		table := map[string]interface{}{}
+		for {
+			p.skipSpaces()
+			if p.pos < len(p.text) && p.text[p.pos] == '}' {
+				p.pos++
+				return table, nil
+			}
+			eq := indexOutsideQuotes(p.text[p.pos:], '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("expected key = value in inline table")
+			}
+			keys, err := splitTOMLKey(p.text[p.pos : p.pos+eq])
+			if err != nil {
+				return nil, err
+			}
+			p.pos += eq + 1
+			value, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			inner, err := tomlTable(table, keys[:len(keys)-1])
+			if err != nil {
+				return nil, err
+			}
+			inner[keys[len(keys)-1]] = value
+			p.skipSpaces()
+			if p.pos < len(p.text) && p.text[p.pos] == ',' {
+				p.pos++
+			} else if p.pos >= len(p.text) || p.text[p.pos] != '}' {
+				return nil, fmt.Errorf("expected \",\" or \"}\" in inline table")
+			}
+		}
+	}
+
+	// Bare value runs until separator
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune(",]} \t", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	return parseTOMLScalar(p.text[start:p.pos])
+}
+
+// Parsing boolean or number
+func parseTOMLScalar(text string) (interface{}, error) {
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	digits := strings.ReplaceAll(text, "_", "")
+	// Only explicitly prefixed integers are in other bases than 10
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(digits, prefix) {
+			if i, err := strconv.ParseInt(digits[len(prefix):], base, 64); err == nil {
+				return i, nil
+			}
+			return nil, fmt.Errorf("unsupported value %q", text)
+		}
+	}
+	// Decimal numbers have no leading zeros, and of letters floats have only exponents
+	unsigned := strings.TrimLeft(digits, "+-")
+	if len(unsigned) > 1 && unsigned[0] == '0' && unsigned[1] >= '0' && unsigned[1] <= '9' ||
+		strings.Trim(unsigned, "0123456789.eE+-") != "" {
+		return nil, fmt.Errorf("unsupported value %q", text)
+	}
+	if i, err := strconv.ParseInt(digits, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(digits, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", text)
+}
+
+// Formatting param value as TOML, arrays and tables of params included
+func formatTOMLValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return "inf"
+		case math.IsInf(v, -1):
+			return "-inf"
+		case math.IsNaN(v):
+			return "nan"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = strconv.Quote(key) + " = " + formatTOMLValue(v[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		elements := make([]string, rv.Len())
+		for i := range elements {
+			elements[i] = formatTOMLValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	return fmt.Sprint(value)
+}
+
+// Writing instance as TOML readable by readInstanceFromTOML, params included
+func writeTOML(w io.Writer, instance *Instance) error {
+	bw := bufio.NewWriter(w)
+	// Tables are separated by blank lines, except at the start of the file
+	header := func(name string) {
+		if bw.Buffered() > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintln(bw, name)
+	}
+	if instance.Name != "" {
+		fmt.Fprintf(bw, "name = %s\n", strconv.Quote(instance.Name))
+	}
+	if instance.Capacity > 0 {
+		fmt.Fprintf(bw, "capacity = %s\n", formatFloat(instance.Capacity))
+	}
+	if instance.KnownOptimum > 0 {
+		fmt.Fprintf(bw, "known_optimum = %d\n", instance.KnownOptimum)
+	}
+	if len(instance.Conflicts) > 0 {
+		pairs := make([]string, len(instance.Conflicts))
+		for i, pair := range instance.Conflicts {
+			pairs[i] = "[" + joinQuoted(pair) + "]"
+		}
+		fmt.Fprintf(bw, "conflicts = [%s]\n", strings.Join(pairs, ", "))
+	}
+	if len(instance.Synergies) > 0 {
+		synergies := make([]string, len(instance.Synergies))
+		for i, synergy := range instance.Synergies {
+			synergies[i] = fmt.Sprintf("{items = [%s], bonus = %d}", joinQuoted(synergy.Items), synergy.Bonus)
+		}
+		fmt.Fprintf(bw, "synergies = [%s]\n", strings.Join(synergies, ", "))
+	}
+
+	if len(instance.Params) > 0 {
+		keys := make([]string, 0, len(instance.Params))
+		for key := range instance.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		header("[params]")
+		for _, key := range keys {
+			fmt.Fprintf(bw, "%s = %s\n", key, formatTOMLValue(instance.Params[key]))
+		}
+	}
+
+	if len(instance.Curves) > 0 {
+		header("[curves]")
+		for _, category := range sortedCategories(instance.Curves) {
+			points := make([]string, len(instance.Curves[category]))
+			for i, point := range instance.Curves[category] {
+				points[i] = fmt.Sprintf("{count = %s, factor = %s}", formatFloat(point.Count), formatFloat(point.Factor))
+			}
+			fmt.Fprintf(bw, "%s = [%s]\n", strconv.Quote(category), strings.Join(points, ", "))
+		}
+	}
+
+	if len(instance.Resources) > 0 {
+		header("[resources]")
+		for _, resource := range sortedResources(instance.Resources) {
+			fmt.Fprintf(bw, "%s = %s\n", strconv.Quote(resource), formatFloat(instance.Resources[resource]))
+		}
+	}
+
+	if len(instance.CategoryLimits) > 0 {
+		header("[category_limits]")
+		for _, category := range sortedLimitCategories(instance.CategoryLimits) {
+			limit := instance.CategoryLimits[category]
+			fmt.Fprintf(bw, "%s = {weight = %s, count = %d}\n", strconv.Quote(category), formatFloat(limit.Weight), limit.Count)
+		}
+	}
+
+	for _, item := range instance.Items {
+		header("[[items]]")
+		fmt.Fprintf(bw, "name = %s\n", strconv.Quote(item.Name))
+		fmt.Fprintf(bw, "weight = %s\n", formatFloat(item.Weight))
+		fmt.Fprintf(bw, "value = %d\n", item.Value)
+		if item.WeightSD != 0 {
+			fmt.Fprintf(bw, "weight_sd = %s\n", formatFloat(item.WeightSD))
+		}
+		if item.Risk != 0 {
+			fmt.Fprintf(bw, "risk = %s\n", formatFloat(item.Risk))
+		}
+		if item.Category != "" {
+			fmt.Fprintf(bw, "category = %s\n", strconv.Quote(item.Category))
+		}
+		if item.Owner != "" {
+			fmt.Fprintf(bw, "owner = %s\n", strconv.Quote(item.Owner))
+		}
+		if item.Group != "" {
+			fmt.Fprintf(bw, "group = %s\n", strconv.Quote(item.Group))
+		}
+		if len(item.Requires) > 0 {
+			fmt.Fprintf(bw, "requires = [%s]\n", joinQuoted(item.Requires))
+		}
+		if item.Required {
+			fmt.Fprintf(bw, "required = true\n")
+		}
+		if item.Excluded {
+			fmt.Fprintf(bw, "excluded = true\n")
+		}
+		if item.Quantity != 0 {
+			fmt.Fprintf(bw, "quantity = %d\n", item.Quantity)
+		}
+		if len(item.Weights) > 0 {
+			fmt.Fprintf(bw, "weights = [%s]\n", joinFloats(item.Weights))
+		}
+		if len(item.Values) > 0 {
+			fmt.Fprintf(bw, "values = [%s]\n", joinInts(item.Values))
+		}
+		if len(item.Resources) > 0 {
+			usages := make([]string, 0, len(item.Resources))
+			for _, resource := range sortedResources(item.Resources) {
+				usages = append(usages, fmt.Sprintf("%s = %s", strconv.Quote(resource), formatFloat(item.Resources[resource])))
+			}
+			fmt.Fprintf(bw, "resources = {%s}\n", strings.Join(usages, ", "))
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOMLScalar(t *testing.T) {
+	tests := []struct {
+		text string
+		want interface{}
+	}{
+		{"10", int64(10)},
+		{"-17", int64(-17)},
+		{"+3", int64(3)},
+		{"0", int64(0)},
+		{"1_000", int64(1000)},
+		{"0x1F", int64(31)},
+		{"0o17", int64(15)},
+		{"0b101", int64(5)},
+		{"1.5", 1.5},
+		{"0.25", 0.25},
+		{"-0.5e3", -500.0},
+		{"6e-2", 0.06},
+		{"true", true},
+		{"false", false},
+		{"inf", math.Inf(1)},
+		{"-inf", math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		got, err := parseTOMLScalar(tt.text)
+		if err != nil {
+			t.Errorf("%s: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s is %#v, want %#v", tt.text, got, tt.want)
+		}
+	}
+
+	// Leading zeros are not decimal numbers in TOML, let alone octal ones
+	for _, text := range []string{"010", "-07", "00.5", "0x", "0b102", "infinity", "1e", "12abc"} {
+		if got, err := parseTOMLScalar(text); err == nil {
+			t.Errorf("%s parsed as %#v, expected an error", text, got)
+		}
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`# Instance
+name = "camping" # trailing comment
+capacity = 5.5
+"a=b" = 1
+tags = ["x", "y,z",
+  "w"]
+
+[params]
+seed = 42
+solver = 'dp'
+point = {x = 1, y = [2, 3]}
+
+[[items]]
+name = "tent"
+weight = 2
+value = 30
+
+[[items]]
+name = "rope # not a comment"
+weight = 0.5
+value = 0x10
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":     "camping",
+		"capacity": 5.5,
+		"a=b":      int64(1),
+		"tags":     []interface{}{"x", "y,z", "w"},
+		"params": map[string]interface{}{
+			"seed":   int64(42),
+			"solver": "dp",
+			"point":  map[string]interface{}{"x": int64(1), "y": []interface{}{int64(2), int64(3)}},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"name": "tent", "weight": int64(2), "value": int64(30)},
+			map[string]interface{}{"name": "rope # not a comment", "weight": 0.5, "value": int64(16)},
+		},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("parsed\n%#v\nwant\n%#v", doc, want)
+	}
+
+	for _, text := range []string{"x = 010", "x", "x = [1, 2", "x = 1 2", "[[items]]\nname = \"a\"\nname = \"b\""} {
+		if _, err := parseTOML(text); err == nil {
+			t.Errorf("%q parsed, expected an error", text)
+		}
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	instance := &Instance{
+		Name:         "camping",
+		Capacity:     5.5,
+		KnownOptimum: 60,
+		Items: []Item{
+			{Name: "tent", Weight: 2, Value: 30, Category: "shelter", Requires: []string{"pegs"}, Quantity: 2},
+			{Name: "pegs", Weight: 0.25, Value: 1, Required: true},
+			{Name: `quoted "rope"`, Weight: 0.5, Value: 5, Group: "g", Owner: "ann", Risk: 0.1},
+		},
+		Conflicts: [][]string{{"tent", "pegs"}},
+		Synergies: []Synergy{{Items: []string{"tent", "pegs"}, Bonus: 3}},
+		Curves:    map[string]Curve{"shelter": {{Count: 1, Factor: 1}, {Count: 3, Factor: 0.5}}},
+		Resources: map[string]float64{"volume": 3},
+		CategoryLimits: map[string]CategoryLimit{
+			"shelter": {Weight: 4, Count: 2},
+		},
+		Params: map[string]interface{}{
+			"seed":     int64(7),
+			"solver":   "dp",
+			"exclude":  []interface{}{"a", "b c"},
+			"weights":  []float64{0.5, 2},
+			"features": []string{"fallback"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeTOML(&buf, instance); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `exclude = ["a", "b c"]`) {
+		t.Errorf("array param not written as TOML array:\n%s", buf.String())
+	}
+	read, err := readInstanceFromTOML(&buf, true)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+
+	// Arrays of params are read back as generic arrays, numbers as int64 or float64
+	instance.Params["weights"] = []interface{}{0.5, int64(2)}
+	instance.Params["features"] = []interface{}{"fallback"}
+	if !reflect.DeepEqual(read, instance) {
+		t.Errorf("read back\n%#v\nwant\n%#v", read, instance)
+	}
+}
+
+func TestInstanceParamsRestricted(t *testing.T) {
+	newFlags := func() (*flag.FlagSet, *string, *float64) {
+		fs := flag.NewFlagSet("solve", flag.ContinueOnError)
+		output := fs.String("output", "-", "")
+		maxTemp := fs.Float64("max-temp", 1000, "")
+		return fs, output, maxTemp
+	}
+
+	instance, err := readInstanceFromTOML(strings.NewReader("[params]\nmax_temp = 50\n\n[[items]]\nname = \"a\"\nweight = 1\nvalue = 1\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, _, maxTemp := newFlags()
+	if err := applyInstance(fs, instance); err != nil {
+		t.Fatal(err)
+	}
+	if *maxTemp != 50 {
+		t.Errorf("max-temp is %v, want 50", *maxTemp)
+	}
+
+	// Instances may come from URLs, they must not name files to write or enable features
+	for _, key := range []string{"output", "save_solution", "manifest", "pool_export", "run_store", "features"} {
+		fs, output, _ := newFlags()
+		instance := &Instance{Params: map[string]interface{}{key: "/tmp/pwned.txt"}}
+		if err := applyInstance(fs, instance); err == nil {
+			t.Errorf("%s accepted from instance params", key)
+		}
+		if *output != "-" {
+			t.Errorf("%s: output changed to %q", key, *output)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Severity of input problem: errors make instance unsolvable, warnings are suspicious but allowed
+const (
+	problemError   = "error"
+	problemWarning = "warning"
+)
+
+// Problem found in the instance by validation
+type Problem struct {
+	Severity string `json:"severity"`
+	// Index of offending item, -1 for problems of the whole instance
+	Item int `json:"item"`
+	// Offending field, like "weight" or "capacity"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Item < 0 {
+		return fmt.Sprintf("%s: %s: %s", p.Severity, p.Field, p.Message)
+	}
+	return fmt.Sprintf("%s: item %d %s: %s", p.Severity, p.Item+1, p.Field, p.Message)
+}
+
+// Checking instance for values that would make solving produce nonsense, against given capacity.
+// All problems are returned, not just the first one.
+func validateInstance(instance *Instance, capacity float64) []Problem {
+	var problems []Problem
+	add := func(severity string, item int, field, format string, args ...interface{}) {
+		problems = append(problems, Problem{severity, item, field, fmt.Sprintf(format, args...)})
+	}
+
+	if math.IsNaN(capacity) || math.IsInf(capacity, 0) || capacity <= 0 {
+		add(problemError, -1, "capacity", "must be a positive number, got %v", capacity)
+	}
+	if len(instance.Items) == 0 {
+		add(problemError, -1, "items", "instance has no items")
+	}
+	if instance.KnownOptimum < 0 {
+		add(problemError, -1, "known_optimum", "must not be negative, got %d", instance.KnownOptimum)
+	}
+
+	names := map[string]bool{}
+	copies := map[string]int{}
+	for _, item := range instance.Items {
+		names[item.Name] = true
+		copies[item.Name] += max(item.Quantity, 1)
+	}
+	for i, item := range instance.Items {
+		if item.Required && item.Excluded {
+			add(problemError, i, "required", "item is both required and excluded")
+		}
+		for _, name := range item.Requires {
+			switch {
+			case name == item.Name:
+				add(problemWarning, i, "requires", "item requires itself, requirement has no effect")
+			case !names[name]:
+				add(problemWarning, i, "requires", "required item %q not found, item can never be selected", name)
+			}
+		}
+	}
+	for n, synergy := range instance.Synergies {
+		field := fmt.Sprintf("synergies.%d", n+1)
+		if len(synergy.Items) != 2 {
+			add(problemError, -1, field, "must name two items, got %d", len(synergy.Items))
+			continue
+		}
+		for _, name := range synergy.Items {
+			if !names[name] {
+				add(problemWarning, -1, field, "item %q not found, synergy has no effect", name)
+			}
+		}
+		if name := synergy.Items[0]; name == synergy.Items[1] && names[name] && copies[name] < 2 {
+			add(problemWarning, -1, field, "pair of %q needs two copies of it, synergy has no effect", name)
+		}
+	}
+	for n, pair := range instance.Conflicts {
+		field := fmt.Sprintf("conflicts.%d", n+1)
+		if len(pair) != 2 {
+			add(problemError, -1, field, "must name two items, got %d", len(pair))
+			continue
+		}
+		for _, name := range pair {
+			if !names[name] {
+				add(problemWarning, -1, field, "item %q not found, conflict has no effect", name)
+			}
+		}
+	}
+
+	seen := map[string]int{}
+	for i, item := range instance.Items {
+		switch {
+		case math.IsNaN(item.Weight) || math.IsInf(item.Weight, 0):
+			add(problemError, i, "weight", "must be a finite number, got %v", item.Weight)
+		case item.Weight < 0:
+			add(problemError, i, "weight", "must not be negative, got %v", item.Weight)
+		case item.Weight > capacity && capacity > 0:
+			add(problemWarning, i, "weight", "%v is over capacity %v, item can never be selected", item.Weight, capacity)
+		}
+		if item.Value < 0 {
+			add(problemError, i, "value", "must not be negative, got %d", item.Value)
+		}
+		if math.IsNaN(item.WeightSD) || math.IsInf(item.WeightSD, 0) || item.WeightSD < 0 {
+			add(problemError, i, "weight_sd", "must be a finite non-negative number, got %v", item.WeightSD)
+		}
+		if math.IsNaN(item.Risk) || math.IsInf(item.Risk, 0) || item.Risk < 0 {
+			add(problemError, i, "risk", "must be a finite non-negative number, got %v", item.Risk)
+		}
+
+		if item.Quantity < 0 {
+			add(problemError, i, "quantity", "must not be negative, got %d", item.Quantity)
+		}
+		for _, resource := range resourceNames(item) {
+			if usage := item.Resources[resource]; math.IsNaN(usage) || math.IsInf(usage, 0) || usage < 0 {
+				add(problemError, i, "resources."+resource, "must be a finite non-negative number, got %v", usage)
+			}
+		}
+
+		if item.Name == "" {
+			add(problemWarning, i, "name", "item has no name")
+		} else if first, ok := seen[item.Name]; ok {
+			add(problemWarning, i, "name", "duplicate name %q, first used by item %d", item.Name, first+1)
+		} else {
+			seen[item.Name] = i
+		}
+	}
+
+	for _, resource := range sortedResources(instance.Resources) {
+		if capacity := instance.Resources[resource]; math.IsNaN(capacity) || math.IsInf(capacity, 0) || capacity < 0 {
+			add(problemError, -1, "resources."+resource, "capacity must be a finite non-negative number, got %v", capacity)
+		}
+	}
+	for _, resource := range resourceNames(instance.Items...) {
+		if _, ok := instance.Resources[resource]; !ok {
+			add(problemWarning, -1, "resources."+resource, "resource has no capacity, its consumption is not limited")
+		}
+	}
+
+	categories := map[string]bool{}
+	for _, item := range instance.Items {
+		categories[item.Category] = true
+	}
+	for _, category := range sortedLimitCategories(instance.CategoryLimits) {
+		limit := instance.CategoryLimits[category]
+		field := "category_limits." + category
+		if math.IsNaN(limit.Weight) || math.IsInf(limit.Weight, 0) || limit.Weight < 0 {
+			add(problemError, -1, field, "weight must be a finite non-negative number, got %v", limit.Weight)
+		}
+		if limit.Count < 0 {
+			add(problemError, -1, field, "count must not be negative, got %d", limit.Count)
+		}
+		if !categories[category] {
+			add(problemWarning, -1, field, "no item has category %q, limit has no effect", category)
+		}
+	}
+
+	for _, category := range sortedCategories(instance.Curves) {
+		curve := instance.Curves[category]
+		field := fmt.Sprintf("curves.%s", category)
+		for j, point := range curve {
+			if math.IsNaN(point.Factor) || point.Factor < 0 {
+				add(problemError, -1, field, "factor of point %d must not be negative, got %v", j+1, point.Factor)
+			}
+			if j > 0 && !(point.Count > curve[j-1].Count) {
+				add(problemError, -1, field, "counts must be increasing, point %d has %v after %v", j+1, point.Count, curve[j-1].Count)
+			}
+		}
+	}
+	return problems
+}
+
+// Checking if any of the problems is an error
+func hasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == problemError {
+			return true
+		}
+	}
+	return false
+}
+
+// Logging problem at the level of its severity
+func (p Problem) log() {
+	level := slog.LevelWarn
+	if p.Severity == problemError {
+		level = slog.LevelError
+	}
+	args := []any{"field", p.Field}
+	if p.Item >= 0 {
+		args = append([]any{"item", p.Item + 1}, args...)
+	}
+	slog.Log(context.Background(), level, p.Message, args...)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+)
+
+// Largest instance verified by enumerating all subsets
+const maxVerifyItems = 25
+
+// Outcome of checking heuristic result against exact solution
+type verification struct {
+	// Exact method used: dynamic programming or enumeration
+	Method  string
+	Optimal Result
+}
+
+// Choosing exact solver for items and params, nil with the reason if there is none fast enough
+func exactSolver(items []Item, params Params) (Solver, string, error) {
+	dpErr := dpApplicable(items, params)
+	if dpErr == nil {
+		return dpSolver, "dynamic programming", nil
+	}
+	if len(items) <= maxVerifyItems {
+		return exhaustiveSolver, "enumeration", nil
+	}
+	return nil, "", fmt.Errorf("%d items are too many to enumerate and dynamic programming can't be used: %v", len(items), dpErr)
+}
+
+// Solving items exactly with params of the heuristic run
+func verifyResult(ctx context.Context, items []Item, params Params) (*verification, error) {
+	solver, method, err := exactSolver(items, params)
+	if err != nil {
+		return nil, err
+	}
+	params.PoolSize = 0
+	params.OnStep = nil
+	params.Initial = nil
+	return &verification{Method: method, Optimal: solver(ctx, items, params)}, nil
+}
+
+// Print whether result is optimal and how far from the optimum it is
+func showVerification(w io.Writer, v *verification, result Result) {
+	optimum := v.Optimal.Value
+	fmt.Fprintf(w, "\nVerification by %s: optimal value %d\n", v.Method, optimum)
+	switch {
+	case result.Violation > 0 || v.Optimal.Violation > 0:
+		fmt.Fprintf(w, "Soft constraints are violated, values are not comparable\n")
+	case result.Value >= optimum:
+		fmt.Fprintf(w, "Heuristic solution is optimal\n")
+	default:
+		gap := 0.0
+		if optimum > 0 {
+			gap = 100 * float64(optimum-result.Value) / float64(optimum)
+		}
+		fmt.Fprintf(w, "Heuristic solution misses the optimum by %d (%.2f%%)\n", optimum-result.Value, gap)
+	}
+}
+
+// Checking saved solution against items with params, returning every mismatch found
+func checkSolution(saved *savedSolution, items []Item, params Params) []string {
+	var mismatches []string
+	solution, unknown, err := saved.apply(items)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	for _, name := range unknown {
+		mismatches = append(mismatches, fmt.Sprintf("item %q is not in the instance", name))
+	}
+	// Solutions with both names and flags have to agree on them
+	if saved.Items != nil && saved.Solution != nil {
+		if len(saved.Solution) != len(items) {
+			mismatches = append(mismatches, fmt.Sprintf("solution has %d entries for %d items", len(saved.Solution), len(items)))
+		} else {
+			for i := range items {
+				if saved.Solution[i] != solution[i] {
+					mismatches = append(mismatches, fmt.Sprintf("item %d (%s) is %d in solution flags, names disagree",
+						i+1, items[i].Name, saved.Solution[i]))
+				}
+			}
+		}
+	}
+
+	value, weight := newEvaluator(items, params).evaluate(solution)
+	if value != saved.Value {
+		mismatches = append(mismatches, fmt.Sprintf("value is %d, solution reports %d", value, saved.Value))
+	}
+	// Weights differ by summation order at most
+	if math.Abs(weight-saved.Weight) > 1e-9*math.Max(1, weight) {
+		mismatches = append(mismatches, fmt.Sprintf("weight is %s, solution reports %s", formatFloat(weight), formatFloat(saved.Weight)))
+	}
+	if weight > params.MaxWeight {
+		mismatches = append(mismatches, fmt.Sprintf("weight %s exceeds capacity %s", formatFloat(weight), formatFloat(params.MaxWeight)))
+	}
+	for i, item := range items {
+		switch {
+		case item.Required && solution[i] != 1:
+			mismatches = append(mismatches, fmt.Sprintf("required item %s is not selected", item.Name))
+		case item.Excluded && solution[i] == 1:
+			mismatches = append(mismatches, fmt.Sprintf("excluded item %s is selected", item.Name))
+		}
+	}
+	for _, c := range params.Constraints {
+		if dependencies, ok := c.(dependencyLimit); ok {
+			for _, missing := range dependencies.missing(solution, items) {
+				mismatches = append(mismatches, missing+", which is not selected")
+			}
+			continue
+		}
+		if conflicts, ok := c.(conflictLimit); ok {
+			for _, pair := range conflicts.violated(solution, items) {
+				mismatches = append(mismatches, fmt.Sprintf("conflicting items %s are selected together", formatPair(pair)))
+			}
+			continue
+		}
+		if !c.Satisfied(solution, items) {
+			mismatches = append(mismatches, fmt.Sprintf("constraint %s is not satisfied", c.Name()))
+		}
+	}
+	return mismatches
+}
+
+// Verify subcommand: checking solution file against the instance independently of solving
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	input := addInputFlags(fs)
+	solutionFile := fs.String("solution", "", "file with solution written by -save-solution, -manifest or -run-store")
+	maxWeight := fs.Float64("capacity", 5.0, "max weight of the knapsack, capacity of the solution is used if neither this nor input gives one")
+	kahan := fs.Bool("kahan", false, "sum weights with compensated summation")
+	constraints := addConstraintFlags(fs)
+	logging := addLogFlags(fs)
+	fs.Parse(args)
+	logging.apply()
+
+	if *solutionFile == "" {
+		invalid("-solution is required")
+	}
+	saved, err := readSolution(*solutionFile)
+	if err != nil {
+		invalid("Error while reading the solution", "err", err)
+	}
+	instance, err := input.read()
+	if err != nil {
+		invalid("Error while reading the file", "err", err)
+	}
+	capacity := map[string]interface{}{}
+	switch {
+	case instance.Capacity > 0:
+		capacity["capacity"] = instance.Capacity
+	case saved.Capacity > 0:
+		capacity["capacity"] = saved.Capacity
+	}
+	if err := applyConfig(fs, capacity); err != nil {
+		invalid("Error in input capacity", "err", err)
+	}
+	if saved.Capacity > 0 && saved.Capacity != *maxWeight {
+		slog.Info("Solution was found for another capacity", "solution_capacity", saved.Capacity, "capacity", *maxWeight)
+	}
+
+	limits, err := constraints.list(instance, *maxWeight)
+	if err != nil {
+		invalid("Error in constraints", "err", err)
+	}
+	params := Params{
+		MaxWeight:      *maxWeight,
+		CompensatedSum: *kahan,
+		Curves:         instance.Curves,
+		Synergies:      instance.Synergies,
+		Constraints:    limits,
+	}
+	// Solutions of items with quantities name every copy
+	_, items := expandQuantities(instance.Items)
+	mismatches := checkSolution(saved, items, params)
+	for _, m := range mismatches {
+		fmt.Println("Mismatch:", m)
+	}
+	if len(mismatches) > 0 {
+		exit(exitMismatch, "Solution does not match the instance", "mismatches", len(mismatches))
+	}
+	fmt.Printf("Solution is valid: value %d, weight %s, capacity %s\n", saved.Value, formatFloat(saved.Weight), formatFloat(*maxWeight))
+}
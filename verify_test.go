@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Annealing is checked against the exact solvers on small random instances: it never beats
+// the optimum, reaches it on instances this small, and both exact solvers agree on it
+func TestAnnealingVerified(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		items := make([]Item, 4+rnd.Intn(8))
+		total := 0.0
+		for i := range items {
+			items[i] = Item{Name: string(rune('a' + i)), Weight: float64(1+rnd.Intn(40)) / 10, Value: 1 + rnd.Intn(100)}
+			total += items[i].Weight
+		}
+		params := Params{MaxWeight: float64(int(total*5)) / 10, MaxTemp: 100, MinTemp: 0.01, CoolingRate: 0.99,
+			EpochLength: 20, Init: "greedy", Seed: int64(n + 1)}
+
+		v, err := verifyResult(context.Background(), items, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enumerated := exhaustiveSolver(context.Background(), items, params)
+		if v.Optimal.Value != enumerated.Value {
+			t.Errorf("instance %d: %s gives %d, enumeration %d", n, v.Method, v.Optimal.Value, enumerated.Value)
+		}
+		result := simulatedAnnealing(context.Background(), items, params)
+		if result.Weight > params.MaxWeight+1e-9 {
+			t.Errorf("instance %d: annealing weight %v over capacity %v", n, result.Weight, params.MaxWeight)
+		}
+		if result.Value != v.Optimal.Value {
+			t.Errorf("instance %d: annealing gives %d, optimum is %d", n, result.Value, v.Optimal.Value)
+		}
+	}
+}
+
+func TestCheckSolution(t *testing.T) {
+	items := []Item{{Name: "acid", Weight: 2, Value: 10}, {Name: "bleach", Weight: 1, Value: 5},
+		{Name: "water", Weight: 1, Value: 1, Required: true}}
+	params := Params{MaxWeight: 3, Constraints: []Constraint{conflictLimit{Pairs: [][]string{{"acid", "bleach"}}}}}
+
+	valid := &savedSolution{Value: 11, Weight: 3, Items: []string{"acid", "water"}, Solution: []int{1, 0, 1}}
+	if mismatches := checkSolution(valid, items, params); mismatches != nil {
+		t.Errorf("valid solution has mismatches %v", mismatches)
+	}
+
+	wrong := &savedSolution{Value: 20, Weight: 3, Items: []string{"acid", "bleach", "salt"}, Solution: []int{1, 1, 1}}
+	want := []string{
+		`item "salt" is not in the instance`,
+		"item 3 (water) is 1 in solution flags, names disagree",
+		"value is 15, solution reports 20",
+		"required item water is not selected",
+		"conflicting items acid + bleach are selected together",
+	}
+	if mismatches := checkSolution(wrong, items, params); !reflect.DeepEqual(mismatches, want) {
+		t.Errorf("mismatches %q, want %q", mismatches, want)
+	}
+
+	heavy := &savedSolution{Value: 16, Weight: 4, Solution: []int{1, 1, 1}}
+	mismatches := checkSolution(heavy, items, params)
+	if len(mismatches) != 2 || mismatches[0] != "weight 4 exceeds capacity 3" {
+		t.Errorf("overweight solution has mismatches %q", mismatches)
+	}
+}
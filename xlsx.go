@@ -0,0 +1,309 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Reading items from Excel workbook. Columns are mapped by "field=column" pairs,
+// where column is a letter ("weight=C") or a header name ("weight=Kg");
+// without mapping name, weight and value columns are found by header names.
+// The first row of the sheet with cells is the header.
+func readItemsFromXLSX(r io.Reader, sheet, columns string) ([]Item, error) {
+	// Zip archive needs random access, so the workbook is read into memory
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx workbook: %w", err)
+	}
+
+	sheetPath, err := xlsxSheetPath(archive, sheet)
+	if err != nil {
+		return nil, err
+	}
+	var strs []string
+	if f := xlsxFile(archive, "xl/sharedStrings.xml"); f != nil {
+		if strs, err = xlsxSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	f := xlsxFile(archive, sheetPath)
+	if f == nil {
+		return nil, fmt.Errorf("sheet file %s is missing", sheetPath)
+	}
+	rows, err := xlsxRows(f, strs)
+	if err != nil {
+		return nil, err
+	}
+	// Header is the first row with cells
+	header := 0
+	for header < len(rows) && len(rows[header]) == 0 {
+		header++
+	}
+	if header == len(rows) {
+		return nil, fmt.Errorf("sheet is empty")
+	}
+
+	mapping, err := xlsxColumnMapping(rows[header], columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for i, row := range rows[header+1:] {
+		cell := func(field string) string {
+			if c := mapping[field]; c < len(row) {
+				return strings.TrimSpace(row[c])
+			}
+			return ""
+		}
+		// Skipping empty rows below the data
+		if cell("name") == "" && cell("weight") == "" && cell("value") == "" {
+			continue
+		}
+
+		line := header + i + 2
+		weight, err := strconv.ParseFloat(cell("weight"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid weight %q", line, cell("weight"))
+		}
+		// Numbers are stored as floats, values have to be whole
+		value, err := strconv.ParseFloat(cell("value"), 64)
+		if err != nil || math.Abs(value-math.Round(value)) > 1e-9 {
+			return nil, fmt.Errorf("row %d: invalid value %q", line, cell("value"))
+		}
+		items = append(items, Item{Name: cell("name"), Weight: weight, Value: int(math.Round(value))})
+	}
+	return items, nil
+}
+
+// Resolving column indexes of name, weight and value fields
+func xlsxColumnMapping(header []string, columns string) (map[string]int, error) {
+	spec := map[string]string{"name": "name", "weight": "weight", "value": "value"}
+	if columns != "" {
+		for _, pair := range strings.Split(columns, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			field := strings.ToLower(strings.TrimSpace(kv[0]))
+			if _, ok := spec[field]; !ok || len(kv) != 2 {
+				return nil, fmt.Errorf("invalid column mapping %q, expected name=, weight= or value=", pair)
+			}
+			spec[field] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	mapping := map[string]int{}
+	for field, column := range spec {
+		index := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				index = i
+				break
+			}
+		}
+		// Column letters are used if there is no header with such name
+		if index < 0 {
+			if c, ok := xlsxColumnIndex(column); ok && columns != "" {
+				index = c
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("no column %q for %s", column, field)
+		}
+		mapping[field] = index
+	}
+	return mapping, nil
+}
+
+// Converting column letters to zero-based index: "A" is 0, "AA" is 26
+func xlsxColumnIndex(letters string) (int, bool) {
+	if letters == "" {
+		return 0, false
+	}
+	index := 0
+	for _, c := range strings.ToUpper(letters) {
+		if c < 'A' || c > 'Z' {
+			return 0, false
+		}
+		index = index*26 + int(c-'A') + 1
+		if index > xlsxMaxColumns {
+			return 0, false
+		}
+	}
+	return index - 1, true
+}
+
+func xlsxFile(archive *zip.Reader, name string) *zip.File {
+	for _, f := range archive.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func xlsxDecode(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// Finding worksheet file by sheet name, the first sheet if name is empty
+func xlsxSheetPath(archive *zip.Reader, name string) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wb, rf := xlsxFile(archive, "xl/workbook.xml"), xlsxFile(archive, "xl/_rels/workbook.xml.rels")
+	if wb == nil || rf == nil {
+		return "", fmt.Errorf("workbook description is missing")
+	}
+	if err := xlsxDecode(wb, &workbook); err != nil {
+		return "", err
+	}
+	if err := xlsxDecode(rf, &rels); err != nil {
+		return "", err
+	}
+
+	for _, sheet := range workbook.Sheets {
+		if name != "" && sheet.Name != name {
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID != sheet.ID {
+				continue
+			}
+			// Targets are relative to xl/ directory unless absolute
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+		return "", fmt.Errorf("sheet %q has no file", sheet.Name)
+	}
+	if name == "" {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+	return "", fmt.Errorf("workbook has no sheet %q", name)
+}
+
+// Reading shared strings table
+func xlsxSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xlsxDecode(f, &sst); err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		// Rich text is split into runs
+		text := item.T
+		for _, run := range item.Runs {
+			text += run.T
+		}
+		strs[i] = text
+	}
+	return strs, nil
+}
+
+// Most rows and columns an Excel sheet has, column XFD is the last one
+const (
+	xlsxMaxRows    = 1 << 20
+	xlsxMaxColumns = 1 << 14
+)
+
+// Reading cell texts of sheet rows, rows and cells are placed by their references,
+// so that rows left out of the sheet data are empty
+func xlsxRows(f *zip.File, strs []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			Ref   string `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xlsxDecode(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		// Rows without reference follow the previous one
+		i := len(rows)
+		if row.Ref != "" {
+			n, err := strconv.Atoi(row.Ref)
+			if err != nil || n < len(rows)+1 || n > xlsxMaxRows {
+				return nil, fmt.Errorf("row %s: invalid row reference", row.Ref)
+			}
+			i = n - 1
+		}
+		for len(rows) <= i {
+			rows = append(rows, nil)
+		}
+		// Cells without reference follow the previous one
+		column := -1
+		for _, c := range row.Cells {
+			column++
+			if c.Ref != "" {
+				letters := strings.TrimRight(c.Ref, "0123456789")
+				index, ok := xlsxColumnIndex(letters)
+				if !ok || index < column || c.Ref[len(letters):] != strconv.Itoa(i+1) {
+					return nil, fmt.Errorf("cell %s: invalid cell reference in row %d", c.Ref, i+1)
+				}
+				column = index
+			}
+
+			text := c.Value
+			switch c.Type {
+			case "s":
+				index, err := strconv.Atoi(c.Value)
+				if err != nil || index < 0 || index >= len(strs) {
+					return nil, fmt.Errorf("cell %s: invalid shared string %q", c.Ref, c.Value)
+				}
+				text = strs[index]
+			case "inlineStr":
+				text = c.Inline
+			}
+
+			for len(rows[i]) <= column {
+				rows[i] = append(rows[i], "")
+			}
+			rows[i][column] = text
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Workbook with one sheet of the given sheet data
+func xlsxWorkbook(t *testing.T, sheetData string) []byte {
+	t.Helper()
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Items" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>name</t></si><si><t>weight</t></si><si><t>value</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXGaps(t *testing.T) {
+	// Header starts at row 2 and column B, row 4 is left out, cells of row 5 skip column C
+	// and the last cell of row 3 has no reference
+	data := xlsxWorkbook(t, `
+    <row r="2"><c r="B2" t="s"><v>0</v></c><c r="C2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c></row>
+    <row r="3"><c r="B3" t="inlineStr"><is><t>rope</t></is></c><c r="C3"><v>1.5</v></c><c><v>10</v></c></row>
+    <row r="5"><c r="B5" t="inlineStr"><is><t>tent</t></is></c><c r="D5"><v>30</v></c><c r="C5"><v>4</v></c></row>`)
+	_, err := readItemsFromXLSX(bytes.NewReader(data), "", "")
+	// Cells are out of order in row 5
+	if err == nil || !strings.Contains(err.Error(), "cell C5") {
+		t.Fatalf("expected error of cell C5, got %v", err)
+	}
+
+	data = xlsxWorkbook(t, `
+    <row r="2"><c r="B2" t="s"><v>0</v></c><c r="C2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c></row>
+    <row r="3"><c r="B3" t="inlineStr"><is><t>rope</t></is></c><c r="C3"><v>1.5</v></c><c><v>10</v></c></row>
+    <row r="5"><c r="B5" t="inlineStr"><is><t>tent</t></is></c><c r="D5"><v>30</v></c></row>
+    <row><c r="B6" t="inlineStr"><is><t>map</t></is></c><c r="C6"><v>0.1</v></c><c r="D6"><v>5</v></c></row>`)
+	items, err := readItemsFromXLSX(bytes.NewReader(data), "", "")
+	// Tent has no weight in row 5
+	if err == nil || !strings.Contains(err.Error(), "row 5") {
+		t.Fatalf("expected error of row 5, got %v", err)
+	}
+
+	data = xlsxWorkbook(t, `
+    <row r="2"><c r="B2" t="s"><v>0</v></c><c r="C2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c></row>
+    <row r="3"><c r="B3" t="inlineStr"><is><t>rope</t></is></c><c r="C3"><v>1.5</v></c><c><v>10</v></c></row>
+    <row r="5"><c r="B5" t="inlineStr"><is><t>tent</t></is></c><c r="C5"><v>4</v></c><c r="D5"><v>30</v></c></row>
+    <row><c r="B6" t="inlineStr"><is><t>map</t></is></c><c r="C6"><v>0.1</v></c><c r="D6"><v>5</v></c></row>`)
+	if items, err = readItemsFromXLSX(bytes.NewReader(data), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{Name: "rope", Weight: 1.5, Value: 10}, {Name: "tent", Weight: 4, Value: 30}, {Name: "map", Weight: 0.1, Value: 5}}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i].Name != want[i].Name || items[i].Weight != want[i].Weight || items[i].Value != want[i].Value {
+			t.Errorf("item %d is %+v, want %+v", i+1, items[i], want[i])
+		}
+	}
+}
+
+func TestXLSXInvalidReferences(t *testing.T) {
+	tests := []struct {
+		name, sheetData, want string
+	}{
+		{"column past XFD", `<row r="1"><c r="ZZZZZZ1"><v>1</v></c></row>`, "cell ZZZZZZ1"},
+		{"column XFE", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, "cell XFE1"},
+		{"no column", `<row r="1"><c r="12"><v>1</v></c></row>`, "cell 12"},
+		{"other row", `<row r="1"><c r="A2"><v>1</v></c></row>`, "cell A2"},
+		{"bad characters", `<row r="1"><c r="A-1"><v>1</v></c></row>`, "cell A-1"},
+		{"row past the last one", `<row r="1048577"><c><v>1</v></c></row>`, "row 1048577"},
+		{"rows out of order", `<row r="2"><c><v>1</v></c></row><row r="1"><c><v>1</v></c></row>`, "row 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readItemsFromXLSX(bytes.NewReader(xlsxWorkbook(t, tt.sheetData)), "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error of %s, got %v", tt.want, err)
+			}
+		})
+	}
+	if index, ok := xlsxColumnIndex("XFD"); !ok || index != xlsxMaxColumns-1 {
+		t.Errorf("column XFD is %d, %v", index, ok)
+	}
+}
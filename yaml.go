@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Minimal YAML reader covering what hand-maintained item lists need:
+// block mappings and sequences, flow collections on one line, quoted and plain scalars and comments.
+// Anchors, tags, multi-line scalars and multiple documents are not supported.
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// Reading instance from YAML, in the same shapes as JSON input
+func readInstanceFromYAML(r io.Reader, strict bool) (*Instance, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := parseYAML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parsed document has the same shape as JSON one, so it's decoded the same way
+	encoded, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	return decodeInstanceJSON(encoded, jsonOptions{Strict: strict})
+}
+
+// Parsing YAML document into maps, slices and scalar values
+func parseYAML(data string) (interface{}, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(data, "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed in indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	node, err := p.parseNode(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return node, nil
+}
+
+// Parsing block node starting at current line
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+// Parsing block sequence with items at given indent
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	seq := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && !isYAMLSeqItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", line.num)
+		}
+
+		rest := line.text[1:]
+		content := strings.TrimLeft(rest, " ")
+		if content == "" {
+			// Item is a nested block on the following lines
+			p.pos++
+			node, err := p.parseNested(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, node)
+			continue
+		}
+
+		if isYAMLSeqItem(content) || isYAMLMapEntry(content) {
+			// Item starts on the same line, treating its content as a line of its own
+			column := indent + 1 + len(rest) - len(content)
+			p.lines[p.pos] = yamlLine{num: line.num, indent: column, text: content}
+			node, err := p.parseNode(column)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, node)
+			continue
+		}
+
+		value, err := parseYAMLValue(content, line.num)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, value)
+		p.pos++
+	}
+	return seq, nil
+}
+
+// Parsing block mapping with keys at given indent
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || isYAMLSeqItem(line.text) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", line.num)
+		}
+
+		key, rest, ok := splitYAMLMapEntry(line.text)
+		if !ok {
+			return nil, fmt.Errorf("yaml line %d: expected \"key: value\"", line.num)
+		}
+		key, err := yamlKey(key, line.num)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", line.num, key)
+		}
+		p.pos++
+
+		if rest == "" {
+			// Value is a nested block, sequences may stay at the same indent as the key
+			m[key], err = p.parseNested(indent, true)
+		} else {
+			m[key], err = parseYAMLValue(rest, line.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Parsing block nested under the line before, null if there is none
+func (p *yamlParser) parseNested(indent int, seqAtSameIndent bool) (interface{}, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (seqAtSameIndent && next.indent == indent && isYAMLSeqItem(next.text)) {
+		return p.parseNode(next.indent)
+	}
+	return nil, nil
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isYAMLMapEntry(text string) bool {
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		return false
+	}
+	_, _, ok := splitYAMLMapEntry(text)
+	return ok
+}
+
+// Splitting "key: value" line at the first colon outside quotes which is followed by space or line end
+func splitYAMLMapEntry(text string) (key, value string, ok bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func yamlKey(key string, line int) (string, error) {
+	value, err := parseYAMLScalar(key, line)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Cutting comment off the line, "#" starts a comment at line start or after whitespace outside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// Parsing inline value: flow collection or scalar
+func parseYAMLValue(text string, line int) (interface{}, error) {
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		f := &yamlFlow{text: text, line: line}
+		value, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		if f.skipSpaces(); f.pos != len(f.text) {
+			return nil, fmt.Errorf("yaml line %d: unexpected %q after flow collection", line, f.text[f.pos:])
+		}
+		return value, nil
+	}
+	return parseYAMLScalar(text, line)
+}
+
+// Resolving scalar: quoted string, null, boolean, number or plain string
+func parseYAMLScalar(text string, line int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: invalid quoted string %s", line, text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("yaml line %d: invalid quoted string %s", line, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
+
+// Parser of single-line flow collections: {a: 1, b: [x, y]}
+type yamlFlow struct {
+	text string
+	pos  int
+	line int
+}
+
+func (f *yamlFlow) skipSpaces() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) parse() (interface{}, error) {
+	f.skipSpaces()
+	if f.pos >= len(f.text) {
+		return nil, fmt.Errorf("yaml line %d: unterminated flow collection", f.line)
+	}
+	switch f.text[f.pos] {
+	case '{':
+		return f.parseCollection('}')
+	case '[':
+		return f.parseCollection(']')
+	}
+
+	// Scalar runs until the next separator outside quotes
+	start := f.pos
+	var quote byte
+	for ; f.pos < len(f.text); f.pos++ {
+		c := f.text[f.pos]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		if c == ',' || c == '}' || c == ']' || (c == ':' && (f.pos+1 == len(f.text) || f.text[f.pos+1] == ' ')) {
+			break
+		}
+	}
+	return parseYAMLScalar(strings.TrimSpace(f.text[start:f.pos]), f.line)
+}
+
+// Parsing flow mapping or sequence ending with given character
+func (f *yamlFlow) parseCollection(end byte) (interface{}, error) {
+	f.pos++
+	m := map[string]interface{}{}
+	seq := []interface{}{}
+	for {
+		f.skipSpaces()
+		if f.pos < len(f.text) && f.text[f.pos] == end {
+			f.pos++
+			break
+		}
+
+		value, err := f.parse()
+		if err != nil {
+			return nil, err
+		}
+		if end == '}' {
+			f.skipSpaces()
+			if f.pos >= len(f.text) || f.text[f.pos] != ':' {
+				return nil, fmt.Errorf("yaml line %d: expected \":\" in flow mapping", f.line)
+			}
+			f.pos++
+			item, err := f.parse()
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(value)] = item
+		} else {
+			seq = append(seq, value)
+		}
+
+		f.skipSpaces()
+		if f.pos >= len(f.text) {
+			return nil, fmt.Errorf("yaml line %d: unterminated flow collection", f.line)
+		}
+		if f.text[f.pos] == ',' {
+			f.pos++
+		} else if f.text[f.pos] != end {
+			return nil, fmt.Errorf("yaml line %d: expected \",\" in flow collection", f.line)
+		}
+	}
+	if end == '}' {
+		return m, nil
+	}
+	return seq, nil
+}
+
+// Writing instance as YAML readable by readInstanceFromYAML
+func writeYAML(w io.Writer, instance *Instance) error {
+	bw := bufio.NewWriter(w)
+	if instance.Name != "" {
+		fmt.Fprintf(bw, "name: %s\n", strconv.Quote(instance.Name))
+	}
+	if instance.Capacity > 0 {
+		fmt.Fprintf(bw, "capacity: %s\n", formatFloat(instance.Capacity))
+	}
+	if instance.KnownOptimum > 0 {
+		fmt.Fprintf(bw, "known_optimum: %d\n", instance.KnownOptimum)
+	}
+	if len(instance.Curves) > 0 {
+		fmt.Fprintln(bw, "curves:")
+		for _, category := range sortedCategories(instance.Curves) {
+			fmt.Fprintf(bw, "  %s:\n", strconv.Quote(category))
+			for _, point := range instance.Curves[category] {
+				fmt.Fprintf(bw, "    - {count: %s, factor: %s}\n", formatFloat(point.Count), formatFloat(point.Factor))
+			}
+		}
+	}
+
+	if len(instance.Resources) > 0 {
+		fmt.Fprintln(bw, "resources:")
+		for _, resource := range sortedResources(instance.Resources) {
+			fmt.Fprintf(bw, "  %s: %s\n", strconv.Quote(resource), formatFloat(instance.Resources[resource]))
+		}
+	}
+
+	if len(instance.CategoryLimits) > 0 {
+		fmt.Fprintln(bw, "category_limits:")
+		for _, category := range sortedLimitCategories(instance.CategoryLimits) {
+			limit := instance.CategoryLimits[category]
+			fmt.Fprintf(bw, "  %s: {weight: %s, count: %d}\n", strconv.Quote(category), formatFloat(limit.Weight), limit.Count)
+		}
+	}
+
+	if len(instance.Synergies) > 0 {
+		fmt.Fprintln(bw, "synergies:")
+		for _, synergy := range instance.Synergies {
+			fmt.Fprintf(bw, "  - {items: [%s], bonus: %d}\n", joinQuoted(synergy.Items), synergy.Bonus)
+		}
+	}
+
+	if len(instance.Conflicts) > 0 {
+		fmt.Fprintln(bw, "conflicts:")
+		for _, pair := range instance.Conflicts {
+			fmt.Fprintf(bw, "  - [%s]\n", joinQuoted(pair))
+		}
+	}
+
+	fmt.Fprintln(bw, "items:")
+	for _, item := range instance.Items {
+		fmt.Fprintf(bw, "  - name: %s\n", strconv.Quote(item.Name))
+		fmt.Fprintf(bw, "    weight: %s\n", formatFloat(item.Weight))
+		fmt.Fprintf(bw, "    value: %d\n", item.Value)
+		if item.WeightSD != 0 {
+			fmt.Fprintf(bw, "    weight_sd: %s\n", formatFloat(item.WeightSD))
+		}
+		if item.Risk != 0 {
+			fmt.Fprintf(bw, "    risk: %s\n", formatFloat(item.Risk))
+		}
+		if item.Category != "" {
+			fmt.Fprintf(bw, "    category: %s\n", strconv.Quote(item.Category))
+		}
+		if item.Owner != "" {
+			fmt.Fprintf(bw, "    owner: %s\n", strconv.Quote(item.Owner))
+		}
+		if item.Group != "" {
+			fmt.Fprintf(bw, "    group: %s\n", strconv.Quote(item.Group))
+		}
+		if len(item.Requires) > 0 {
+			fmt.Fprintf(bw, "    requires: [%s]\n", joinQuoted(item.Requires))
+		}
+		if item.Required {
+			fmt.Fprintf(bw, "    required: true\n")
+		}
+		if item.Excluded {
+			fmt.Fprintf(bw, "    excluded: true\n")
+		}
+		if item.Quantity != 0 {
+			fmt.Fprintf(bw, "    quantity: %d\n", item.Quantity)
+		}
+		if len(item.Weights) > 0 {
+			fmt.Fprintf(bw, "    weights: [%s]\n", joinFloats(item.Weights))
+		}
+		if len(item.Values) > 0 {
+			fmt.Fprintf(bw, "    values: [%s]\n", joinInts(item.Values))
+		}
+		if len(item.Resources) > 0 {
+			fmt.Fprintln(bw, "    resources:")
+			for _, resource := range sortedResources(item.Resources) {
+				fmt.Fprintf(bw, "      %s: %s\n", strconv.Quote(resource), formatFloat(item.Resources[resource]))
+			}
+		}
+	}
+	return bw.Flush()
+}
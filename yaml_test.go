@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLScalar(t *testing.T) {
+	tests := []struct {
+		text string
+		want interface{}
+	}{
+		{"12", int64(12)},
+		{"-3", int64(-3)},
+		{"1.25", 1.25},
+		{"2e3", 2000.0},
+		{"true", true},
+		{"False", false},
+		{"~", nil},
+		{"null", nil},
+		{"", nil},
+		{"plain text", "plain text"},
+		{`"quoted: \"text\""`, `quoted: "text"`},
+		{`'it''s'`, "it's"},
+		{`"12"`, "12"},
+	}
+	for _, tt := range tests {
+		got, err := parseYAMLScalar(tt.text, 1)
+		if err != nil {
+			t.Errorf("%s: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s is %#v, want %#v", tt.text, got, tt.want)
+		}
+	}
+	for _, text := range []string{`"unterminated`, `'unterminated`, `'`} {
+		if got, err := parseYAMLScalar(text, 1); err == nil {
+			t.Errorf("%s parsed as %#v, expected an error", text, got)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     interface{}
+	}{
+		{
+			name:     "flow sequence",
+			document: `tags: [a, "b, c", 3, [x, y]]`,
+			want:     map[string]interface{}{"tags": []interface{}{"a", "b, c", int64(3), []interface{}{"x", "y"}}},
+		},
+		{
+			name:     "flow map",
+			document: `point: {x: 1, y: [2.5, z], name: "a: b"}`,
+			want: map[string]interface{}{"point": map[string]interface{}{
+				"x": int64(1), "y": []interface{}{2.5, "z"}, "name": "a: b"}},
+		},
+		{
+			name:     "empty flow collections",
+			document: "a: []\nb: {}",
+			want:     map[string]interface{}{"a": []interface{}{}, "b": map[string]interface{}{}},
+		},
+		{
+			name: "comments",
+			document: `# Leading comment
+name: camping # trailing comment
+url: "http://x/#anchor"
+note: a#b
+---
+`,
+			want: map[string]interface{}{"name": "camping", "url": "http://x/#anchor", "note": "a#b"},
+		},
+		{
+			name: "block sequence of maps",
+			document: `items:
+- name: tent
+  weight: 2
+  requires: [pegs]
+-
+  name: pegs
+  weight: 0.25
+capacity: 5`,
+			want: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "tent", "weight": int64(2), "requires": []interface{}{"pegs"}},
+					map[string]interface{}{"name": "pegs", "weight": 0.25},
+				},
+				"capacity": int64(5),
+			},
+		},
+		{
+			name: "nested blocks",
+			document: `curves:
+  tools:
+    - count: 1
+      factor: 1
+    - - 1
+      - 2
+empty:`,
+			want: map[string]interface{}{
+				"curves": map[string]interface{}{"tools": []interface{}{
+					map[string]interface{}{"count": int64(1), "factor": int64(1)},
+					[]interface{}{int64(1), int64(2)},
+				}},
+				"empty": nil,
+			},
+		},
+		{
+			name:     "bare sequence",
+			document: "- 1\n- two\n",
+			want:     []interface{}{int64(1), "two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsed\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name, document, want string
+	}{
+		{"deeper key", "a: 1\n  b: 2", "line 2: unexpected indentation"},
+		{"deeper item", "- 1\n  - 2", "line 2: unexpected indentation"},
+		{"shallower key", "a:\n    b: 1\n  c: 2", "line 3: unexpected indentation"},
+		{"tab indentation", "a:\n\tb: 1", "line 2: tabs are not allowed"},
+		{"no colon", "a: 1\njust text", "line 2: expected \"key: value\""},
+		{"duplicate key", "a: 1\na: 2", "line 2: duplicate key \"a\""},
+		{"unclosed flow", "a: [1, 2", "line 1"},
+		{"text after flow", "a: [1] x", "line 1: unexpected"},
+		{"bad quote", `a: "x`, "line 1: invalid quoted string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML(tt.document)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// Converting JSON instance to YAML and back keeps all its data
+func TestConvertYAMLRoundTrip(t *testing.T) {
+	document := `{
+  "name": "camping",
+  "capacity": 5.5,
+  "known_optimum": 60,
+  "items": [
+    {"name": "tent", "weight": 2, "value": 30, "category": "shelter", "requires": ["pegs"], "quantity": 2},
+    {"name": "pegs", "weight": 0.25, "value": 1, "required": true},
+    {"name": "rope: 10 m # long", "weight": 0.5, "value": 5, "group": "g", "owner": "ann", "risk": 0.1, "weight_sd": 0.05},
+    {"name": "true", "weight": 1, "value": 0, "excluded": true}
+  ],
+  "conflicts": [["tent", "rope: 10 m # long"]],
+  "synergies": [{"items": ["tent", "pegs"], "bonus": 3}],
+  "curves": {"shelter": [{"count": 1, "factor": 1}, {"count": 3, "factor": 0.5}]},
+  "resources": {"volume": 3},
+  "category_limits": {"shelter": {"weight": 4, "count": 2}}
+}`
+	want, err := decodeInstance(strings.NewReader(document), "json", InputOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var yaml bytes.Buffer
+	if err := writeInstance(&yaml, want, "yaml", ','); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeInstance(bytes.NewReader(yaml.Bytes()), "yaml", InputOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("%v\n%s", err, yaml.String())
+	}
+	got.SHA256, want.SHA256 = "", ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("read back\n%#v\nwant\n%#v\nfrom\n%s", got, want, yaml.String())
+	}
+}
@@ -0,0 +1,875 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Decoder of Zstandard compressed streams (RFC 8878), there is none in the standard library.
+// Frames are decoded block by block, keeping only the window of the last decoded bytes
+// which matches may refer to. Dictionaries are not supported.
+type zstdReader struct {
+	r   *bufio.Reader
+	err error
+	// Decoded bytes of the current frame: history within the window, then unread bytes
+	out  []byte
+	read int
+	// Frame being decoded, nil between frames
+	frame *zstdFrame
+	// Buffers reused by blocks
+	block    []byte
+	literals []byte
+}
+
+// State of a frame carried from block to block
+type zstdFrame struct {
+	window   int
+	lastSeen bool
+	// Content size from the header, -1 if unknown
+	contentSize int64
+	produced    int64
+	checksum    bool
+	digest      xxh64
+	// Repeated offsets, the most recent first
+	reps [3]int
+	// Tables of previous blocks, for treeless literals and repeated sequence tables
+	huffman    *zstdHuffman
+	ll, of, ml *zstdFSE
+}
+
+const (
+	zstdFrameMagic = 0xFD2FB528
+	// Skippable frames have magic numbers 0x184D2A50 to 0x184D2A5F
+	zstdSkippableMagic = 0x184D2A50
+	zstdMaxBlockSize   = 128 << 10
+	// Largest window decoded, the reference decoder's default limit
+	zstdMaxWindow = 1 << 27
+)
+
+var errZstdCorrupt = errors.New("zstd: corrupted input")
+
+func newZstdReader(r io.Reader) *zstdReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &zstdReader{r: br}
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	for z.read == len(z.out) {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.err = z.next()
+	}
+	n := copy(p, z.out[z.read:])
+	z.read += n
+	return n, nil
+}
+
+// Decoding the next block, starting and finishing frames on the way
+func (z *zstdReader) next() error {
+	if z.frame == nil {
+		return z.startFrame()
+	}
+	if z.frame.lastSeen {
+		return z.finishFrame()
+	}
+	// Keeping the window of history only, trimmed when it's twice as large so that
+	// copying it costs little per decoded byte
+	if len(z.out) >= 2*z.frame.window+zstdMaxBlockSize {
+		keep := z.out[len(z.out)-z.frame.window:]
+		z.out = z.out[:copy(z.out, keep)]
+		z.read = len(z.out)
+	}
+	start := len(z.out)
+	if err := z.decodeBlock(); err != nil {
+		return err
+	}
+	z.frame.produced += int64(len(z.out) - start)
+	if z.frame.checksum {
+		z.frame.digest.write(z.out[start:])
+	}
+	return nil
+}
+
+func (z *zstdReader) readFull(n int) ([]byte, error) {
+	if cap(z.block) < n {
+		z.block = make([]byte, n)
+	}
+	z.block = z.block[:n]
+	if _, err := io.ReadFull(z.r, z.block); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("zstd: %w", err)
+	}
+	return z.block, nil
+}
+
+// Reading frame header, skipping skippable frames. End of input between frames ends the stream.
+func (z *zstdReader) startFrame() error {
+	var magic [4]byte
+	if n, err := io.ReadFull(z.r, magic[:]); err != nil {
+		if n == 0 && err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("zstd: truncated frame header")
+	}
+	switch m := binary.LittleEndian.Uint32(magic[:]); {
+	case m&0xFFFFFFF0 == zstdSkippableMagic:
+		header, err := z.readFull(4)
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(io.Discard, z.r, int64(binary.LittleEndian.Uint32(header)))
+		if err != nil {
+			return fmt.Errorf("zstd: truncated skippable frame")
+		}
+		return nil
+	case m != zstdFrameMagic:
+		return fmt.Errorf("zstd: unknown frame magic number %#x", m)
+	}
+
+	descriptor, err := z.r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("zstd: truncated frame header")
+	}
+	if descriptor&0x08 != 0 {
+		return errZstdCorrupt
+	}
+	single := descriptor&0x20 != 0
+	f := &zstdFrame{checksum: descriptor&0x04 != 0, contentSize: -1, reps: [3]int{1, 4, 8}}
+	if !single {
+		wd, err := z.r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("zstd: truncated frame header")
+		}
+		base := 1 << (10 + wd>>3)
+		f.window = base + base/8*int(wd&7)
+	}
+	dictSize := [4]int{0, 1, 2, 4}[descriptor&3]
+	sizeSize := [4]int{0, 2, 4, 8}[descriptor>>6]
+	if single && sizeSize == 0 {
+		sizeSize = 1
+	}
+	header, err := z.readFull(dictSize + sizeSize)
+	if err != nil {
+		return err
+	}
+	if id := zstdLE(header[:dictSize]); id != 0 {
+		return fmt.Errorf("zstd: frames compressed with dictionaries are not supported")
+	}
+	if sizeSize > 0 {
+		f.contentSize = int64(zstdLE(header[dictSize:]))
+		if sizeSize == 2 {
+			f.contentSize += 256
+		}
+	}
+	if single {
+		f.window = int(min(f.contentSize, zstdMaxWindow+1))
+	}
+	if f.window > zstdMaxWindow {
+		return fmt.Errorf("zstd: window of %d bytes is larger than the supported %d", f.window, zstdMaxWindow)
+	}
+	z.frame = f
+	z.out, z.read = z.out[:0], 0
+	return nil
+}
+
+// Checking content size and checksum of the frame after its last block
+func (z *zstdReader) finishFrame() error {
+	f := z.frame
+	if f.contentSize >= 0 && f.produced != f.contentSize {
+		return fmt.Errorf("zstd: frame content is %d bytes, header says %d", f.produced, f.contentSize)
+	}
+	if f.checksum {
+		sum, err := z.readFull(4)
+		if err != nil {
+			return err
+		}
+		if binary.LittleEndian.Uint32(sum) != uint32(f.digest.sum()) {
+			return fmt.Errorf("zstd: checksum mismatch")
+		}
+	}
+	z.frame = nil
+	return nil
+}
+
+func (z *zstdReader) decodeBlock() error {
+	header, err := z.readFull(3)
+	if err != nil {
+		return err
+	}
+	h := zstdLE(header)
+	z.frame.lastSeen = h&1 != 0
+	size := int(h >> 3)
+	if size > zstdMaxBlockSize {
+		return errZstdCorrupt
+	}
+	switch h >> 1 & 3 {
+	case 0:
+		data, err := z.readFull(size)
+		if err != nil {
+			return err
+		}
+		z.out = append(z.out, data...)
+	case 1:
+		b, err := z.r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("zstd: truncated block")
+		}
+		for i := 0; i < size; i++ {
+			z.out = append(z.out, b)
+		}
+	case 2:
+		data, err := z.readFull(size)
+		if err != nil {
+			return err
+		}
+		return z.decodeCompressed(data)
+	default:
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+// Decoding compressed block: literals section followed by sequences section
+func (z *zstdReader) decodeCompressed(data []byte) error {
+	n, err := z.decodeLiterals(data)
+	if err != nil {
+		return err
+	}
+	return z.decodeSequences(data[n:])
+}
+
+// Decoding literals section into z.literals, returning its size
+func (z *zstdReader) decodeLiterals(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, errZstdCorrupt
+	}
+	kind, format := data[0]&3, data[0]>>2&3
+	if kind < 2 {
+		// Raw and RLE literals
+		var size, headerSize int
+		switch format {
+		case 0, 2:
+			size, headerSize = int(data[0]>>3), 1
+		case 1:
+			if len(data) < 2 {
+				return 0, errZstdCorrupt
+			}
+			size, headerSize = int(data[0]>>4)+int(data[1])<<4, 2
+		case 3:
+			if len(data) < 3 {
+				return 0, errZstdCorrupt
+			}
+			size, headerSize = int(data[0]>>4)+int(data[1])<<4+int(data[2])<<12, 3
+		}
+		if size > zstdMaxBlockSize {
+			return 0, errZstdCorrupt
+		}
+		if kind == 0 {
+			if len(data) < headerSize+size {
+				return 0, errZstdCorrupt
+			}
+			z.literals = append(z.literals[:0], data[headerSize:headerSize+size]...)
+			return headerSize + size, nil
+		}
+		if len(data) < headerSize+1 {
+			return 0, errZstdCorrupt
+		}
+		z.literals = z.literals[:0]
+		for i := 0; i < size; i++ {
+			z.literals = append(z.literals, data[headerSize])
+		}
+		return headerSize + 1, nil
+	}
+
+	// Huffman compressed literals, with a new tree or the one of the previous block
+	headerSize, sizeBits := [4]int{3, 3, 4, 5}[format], [4]uint{10, 10, 14, 18}[format]
+	if len(data) < headerSize {
+		return 0, errZstdCorrupt
+	}
+	h := zstdLE(data[:headerSize]) >> 4
+	mask := uint64(1)<<sizeBits - 1
+	size, compressed := int(h&mask), int(h>>sizeBits&mask)
+	if size > zstdMaxBlockSize || len(data) < headerSize+compressed {
+		return 0, errZstdCorrupt
+	}
+	src := data[headerSize : headerSize+compressed]
+	if kind == 2 {
+		table, n, err := readZstdHuffman(src)
+		if err != nil {
+			return 0, err
+		}
+		z.frame.huffman = table
+		src = src[n:]
+	} else if z.frame.huffman == nil {
+		return 0, errZstdCorrupt
+	}
+
+	if cap(z.literals) < size {
+		z.literals = make([]byte, size)
+	}
+	z.literals = z.literals[:size]
+	if format == 0 {
+		if err := z.frame.huffman.decode(src, z.literals); err != nil {
+			return 0, err
+		}
+		return headerSize + compressed, nil
+	}
+	// Four streams after a jump table of the sizes of the first three
+	if len(src) < 6 {
+		return 0, errZstdCorrupt
+	}
+	segment := (size + 3) / 4
+	if segment*3 > size {
+		return 0, errZstdCorrupt
+	}
+	sizes := [4]int{int(binary.LittleEndian.Uint16(src)), int(binary.LittleEndian.Uint16(src[2:])), int(binary.LittleEndian.Uint16(src[4:]))}
+	sizes[3] = len(src) - 6 - sizes[0] - sizes[1] - sizes[2]
+	if sizes[3] < 0 {
+		return 0, errZstdCorrupt
+	}
+	src = src[6:]
+	for i, n := range sizes {
+		end := min((i+1)*segment, size)
+		if err := z.frame.huffman.decode(src[:n], z.literals[i*segment:end]); err != nil {
+			return 0, err
+		}
+		src = src[n:]
+	}
+	return headerSize + compressed, nil
+}
+
+// Literal length and match length codes: baselines and numbers of extra bits
+var (
+	zstdLLBase = [36]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 18, 20, 22, 24, 28, 32, 40, 48, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536}
+	zstdLLBits = [36]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	zstdMLBase = [53]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26,
+		27, 28, 29, 30, 31, 32, 33, 34, 35, 37, 39, 41, 43, 47, 51, 59, 67, 83, 99, 131, 259, 515, 1027, 2051,
+		4099, 8195, 16387, 32771, 65539}
+	zstdMLBits = [53]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 3, 3, 4, 4, 5, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16}
+)
+
+// Predefined distributions of sequence codes
+var (
+	zstdDefaultLL = mustZstdFSE([]int{4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2,
+		2, 3, 2, 1, 1, 1, 1, 1, -1, -1, -1, -1}, 6)
+	zstdDefaultML = mustZstdFSE([]int{1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1, -1, -1}, 6)
+	zstdDefaultOF = mustZstdFSE([]int{1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		-1, -1, -1, -1, -1}, 5)
+)
+
+// Decoding sequences section and executing sequences against literals and history
+func (z *zstdReader) decodeSequences(data []byte) error {
+	if len(data) == 0 {
+		return errZstdCorrupt
+	}
+	count, pos := int(data[0]), 1
+	switch {
+	case count == 255:
+		if len(data) < 3 {
+			return errZstdCorrupt
+		}
+		count, pos = int(data[1])+int(data[2])<<8+0x7F00, 3
+	case count >= 128:
+		if len(data) < 2 {
+			return errZstdCorrupt
+		}
+		count, pos = (count-128)<<8+int(data[1]), 2
+	}
+	if count == 0 {
+		if pos != len(data) {
+			return errZstdCorrupt
+		}
+		z.out = append(z.out, z.literals...)
+		return nil
+	}
+
+	if pos >= len(data) || data[pos]&3 != 0 {
+		return errZstdCorrupt
+	}
+	modes := data[pos]
+	pos++
+	f := z.frame
+	tables := []struct {
+		table    **zstdFSE
+		mode     byte
+		fallback *zstdFSE
+		maxLog   int
+		maxCode  int
+	}{
+		{&f.ll, modes >> 6, zstdDefaultLL, 9, 35},
+		{&f.of, modes >> 4 & 3, zstdDefaultOF, 8, 31},
+		{&f.ml, modes >> 2 & 3, zstdDefaultML, 9, 52},
+	}
+	for _, t := range tables {
+		switch t.mode {
+		case 0:
+			*t.table = t.fallback
+		case 1:
+			if pos >= len(data) || int(data[pos]) > t.maxCode {
+				return errZstdCorrupt
+			}
+			*t.table = &zstdFSE{symbols: []uint8{data[pos]}, bits: []uint8{0}, base: []uint16{0}}
+			pos++
+		case 2:
+			table, n, err := readZstdFSE(data[pos:], t.maxLog, t.maxCode+1)
+			if err != nil {
+				return err
+			}
+			*t.table = table
+			pos += n
+		case 3:
+			if *t.table == nil {
+				return errZstdCorrupt
+			}
+		}
+	}
+
+	stream, err := newZstdBits(data[pos:])
+	if err != nil {
+		return err
+	}
+	llState := int(stream.read(f.ll.log))
+	ofState := int(stream.read(f.of.log))
+	mlState := int(stream.read(f.ml.log))
+	literals := z.literals
+	start := len(z.out)
+	for i := 0; i < count; i++ {
+		llCode, ofCode, mlCode := f.ll.symbols[llState], f.of.symbols[ofState], f.ml.symbols[mlState]
+		if int(llCode) >= len(zstdLLBase) || int(mlCode) >= len(zstdMLBase) || ofCode > 31 {
+			return errZstdCorrupt
+		}
+		offset := 1<<ofCode + int(stream.read(int(ofCode)))
+		match := zstdMLBase[mlCode] + int(stream.read(zstdMLBits[mlCode]))
+		literal := zstdLLBase[llCode] + int(stream.read(zstdLLBits[llCode]))
+		if i < count-1 {
+			llState = f.ll.next(llState, stream)
+			mlState = f.ml.next(mlState, stream)
+			ofState = f.of.next(ofState, stream)
+		}
+
+		// Offsets up to 3 repeat recent ones, shifted by one without literals
+		if offset > 3 {
+			offset -= 3
+			f.reps = [3]int{offset, f.reps[0], f.reps[1]}
+		} else {
+			index := offset
+			if literal == 0 {
+				index++
+			}
+			switch index {
+			case 1:
+				offset = f.reps[0]
+			case 2:
+				offset = f.reps[1]
+				f.reps = [3]int{offset, f.reps[0], f.reps[2]}
+			default:
+				if index == 3 {
+					offset = f.reps[2]
+				} else {
+					offset = f.reps[0] - 1
+				}
+				f.reps = [3]int{offset, f.reps[0], f.reps[1]}
+			}
+		}
+
+		if literal > len(literals) || offset <= 0 ||
+			len(z.out)-start+literal+match > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+		z.out = append(z.out, literals[:literal]...)
+		literals = literals[literal:]
+		if offset > len(z.out) {
+			return errZstdCorrupt
+		}
+		// Matches may overlap the bytes they produce, copied a period at a time
+		for match > 0 {
+			n := min(match, offset)
+			from := len(z.out) - offset
+			z.out = append(z.out, z.out[from:from+n]...)
+			match -= n
+		}
+	}
+	if stream.offset != 0 {
+		return errZstdCorrupt
+	}
+	z.out = append(z.out, literals...)
+	return nil
+}
+
+// Bitstream read backwards from its end, where the highest set bit of the last byte
+// marks the start. Past the start of data it reads zeros, offset goes negative.
+type zstdBits struct {
+	data   []byte
+	offset int
+}
+
+func newZstdBits(data []byte) (*zstdBits, error) {
+	if len(data) == 0 || data[len(data)-1] == 0 {
+		return nil, errZstdCorrupt
+	}
+	return &zstdBits{data: data, offset: len(data)*8 - 9 + bits.Len8(data[len(data)-1])}, nil
+}
+
+func (b *zstdBits) read(n int) uint64 {
+	if n == 0 {
+		return 0
+	}
+	b.offset -= n
+	if b.offset >= 0 {
+		return zstdBitsLE(b.data, b.offset, n)
+	}
+	if n+b.offset <= 0 {
+		return 0
+	}
+	return zstdBitsLE(b.data, 0, n+b.offset) << -b.offset
+}
+
+// Reading n bits, at most 56, starting at bit offset of data in little-endian order.
+// Bytes past the end of data read as zeros.
+func zstdBitsLE(data []byte, offset, n int) uint64 {
+	var v uint64
+	for i, j := 0, offset/8; i < 8 && j < len(data); i, j = i+1, j+1 {
+		v |= uint64(data[j]) << (8 * i)
+	}
+	return v >> (offset % 8) & (1<<n - 1)
+}
+
+// Decoding table of finite state entropy coding
+type zstdFSE struct {
+	log     int
+	symbols []uint8
+	bits    []uint8
+	base    []uint16
+}
+
+func (t *zstdFSE) next(state int, stream *zstdBits) int {
+	return int(t.base[state]) + int(stream.read(int(t.bits[state])))
+}
+
+// Building decoding table from normalized symbol counts, -1 stands for "less than 1"
+func newZstdFSE(counts []int, log int) (*zstdFSE, error) {
+	size := 1 << log
+	t := &zstdFSE{log: log, symbols: make([]uint8, size), bits: make([]uint8, size), base: make([]uint16, size)}
+	next := make([]int, len(counts))
+	high := size
+	for s, count := range counts {
+		if count == -1 {
+			high--
+			t.symbols[high] = uint8(s)
+			next[s] = 1
+		}
+	}
+	step, mask := size>>1+size>>3+3, size-1
+	pos := 0
+	for s, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		next[s] = count
+		for i := 0; i < count; i++ {
+			t.symbols[pos] = uint8(s)
+			pos = (pos + step) & mask
+			for pos >= high {
+				pos = (pos + step) & mask
+			}
+		}
+	}
+	if pos != 0 {
+		return nil, errZstdCorrupt
+	}
+	for i, s := range t.symbols {
+		state := next[s]
+		next[s]++
+		t.bits[i] = uint8(log + 1 - bits.Len(uint(state)))
+		t.base[i] = uint16(state<<t.bits[i] - size)
+	}
+	return t, nil
+}
+
+func mustZstdFSE(counts []int, log int) *zstdFSE {
+	t, err := newZstdFSE(counts, log)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Reading table description of at most maxSymbols symbols, returning its size in bytes
+func readZstdFSE(data []byte, maxLog, maxSymbols int) (*zstdFSE, int, error) {
+	offset := 0
+	read := func(n int) int {
+		v := int(zstdBitsLE(data, offset, n))
+		offset += n
+		return v
+	}
+	log := 5 + read(4)
+	if log > maxLog {
+		return nil, 0, errZstdCorrupt
+	}
+	remaining := 1 << log
+	var counts []int
+	for remaining > 0 && len(counts) < maxSymbols {
+		n := bits.Len(uint(remaining + 1))
+		v := read(n)
+		lower := 1<<(n-1) - 1
+		threshold := 1<<n - 1 - (remaining + 1)
+		if v&lower < threshold {
+			offset--
+			v &= lower
+		} else if v > lower {
+			v -= threshold
+		}
+		count := v - 1
+		remaining -= max(count, -count)
+		counts = append(counts, count)
+		if count == 0 {
+			// Zero counts are followed by 2-bit repeat flags of more zeros
+			for {
+				repeat := read(2)
+				for i := 0; i < repeat && len(counts) < maxSymbols; i++ {
+					counts = append(counts, 0)
+				}
+				if repeat != 3 {
+					break
+				}
+			}
+		}
+	}
+	size := (offset + 7) / 8
+	if remaining != 0 || size > len(data) {
+		return nil, 0, errZstdCorrupt
+	}
+	t, err := newZstdFSE(counts, log)
+	return t, size, err
+}
+
+// Decoding table of canonical Huffman code, indexed by the next maxBits bits
+type zstdHuffman struct {
+	maxBits int
+	symbols []uint8
+	bits    []uint8
+}
+
+// Reading Huffman tree description, returning its size in bytes
+func readZstdHuffman(data []byte) (*zstdHuffman, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	var weights []int
+	header := int(data[0])
+	size := 1
+	if header >= 128 {
+		// Weights of 4 bits each
+		count := header - 127
+		size += (count + 1) / 2
+		if len(data) < size {
+			return nil, 0, errZstdCorrupt
+		}
+		for i := 0; i < count; i++ {
+			weights = append(weights, int(data[1+i/2]>>(4*(1-i%2))&15))
+		}
+	} else {
+		// Weights compressed by finite state entropy with two interleaved states
+		size += header
+		if len(data) < size {
+			return nil, 0, errZstdCorrupt
+		}
+		table, n, err := readZstdFSE(data[1:size], 6, 256)
+		if err != nil {
+			return nil, 0, err
+		}
+		stream, err := newZstdBits(data[1+n : size])
+		if err != nil {
+			return nil, 0, err
+		}
+		states := [2]int{int(stream.read(table.log)), int(stream.read(table.log))}
+		for i := 0; ; i = 1 - i {
+			if len(weights) > 255 {
+				return nil, 0, errZstdCorrupt
+			}
+			weights = append(weights, int(table.symbols[states[i]]))
+			states[i] = table.next(states[i], stream)
+			if stream.offset < 0 {
+				weights = append(weights, int(table.symbols[states[1-i]]))
+				break
+			}
+		}
+	}
+	if len(weights) > 255 {
+		return nil, 0, errZstdCorrupt
+	}
+
+	// Weight of the last symbol completes the sum of powers of two
+	total := 0
+	for _, w := range weights {
+		if w > 11 {
+			return nil, 0, errZstdCorrupt
+		}
+		if w > 0 {
+			total += 1 << (w - 1)
+		}
+	}
+	if total == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	maxBits := bits.Len(uint(total))
+	left := 1<<maxBits - total
+	if maxBits > 11 || left&(left-1) != 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	weights = append(weights, bits.Len(uint(left)))
+
+	// Longer codes come first in the table, symbols of the same length in their order
+	t := &zstdHuffman{maxBits: maxBits, symbols: make([]uint8, 1<<maxBits), bits: make([]uint8, 1<<maxBits)}
+	var rank [13]int
+	for _, w := range weights {
+		if w > 0 {
+			rank[maxBits+1-w]++
+		}
+	}
+	var next [13]int
+	for length, position := maxBits, 0; length >= 1; length-- {
+		next[length] = position
+		position += rank[length] << (maxBits - length)
+	}
+	for s, w := range weights {
+		if w == 0 {
+			continue
+		}
+		length := maxBits + 1 - w
+		for i := 0; i < 1<<(maxBits-length); i++ {
+			t.symbols[next[length]+i] = uint8(s)
+			t.bits[next[length]+i] = uint8(length)
+		}
+		next[length] += 1 << (maxBits - length)
+	}
+	return t, size, nil
+}
+
+// Decoding Huffman stream filling out exactly
+func (t *zstdHuffman) decode(data []byte, out []byte) error {
+	stream, err := newZstdBits(data)
+	if err != nil {
+		return err
+	}
+	mask := 1<<t.maxBits - 1
+	state := int(stream.read(t.maxBits))
+	for i := range out {
+		if stream.offset <= -t.maxBits {
+			return errZstdCorrupt
+		}
+		out[i] = t.symbols[state]
+		n := int(t.bits[state])
+		state = (state<<n | int(stream.read(n))) & mask
+	}
+	if stream.offset != -t.maxBits {
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+// Little-endian unsigned number of up to 8 bytes
+func zstdLE(data []byte) uint64 {
+	var v uint64
+	for i, b := range data {
+		v |= uint64(b) << (8 * i)
+	}
+	return v
+}
+
+// Streaming XXH64 with seed 0, zstd frame checksums are its low 32 bits
+type xxh64 struct {
+	v     [4]uint64
+	total uint64
+	mem   [32]byte
+	n     int
+	init  bool
+}
+
+// Variables rather than constants, the seeding arithmetic wraps around
+var (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+func xxhRound(acc, input uint64) uint64 {
+	return bits.RotateLeft64(acc+input*xxhPrime2, 31) * xxhPrime1
+}
+
+func (d *xxh64) write(p []byte) {
+	if !d.init {
+		d.v = [4]uint64{xxhPrime1 + xxhPrime2, xxhPrime2, 0, -xxhPrime1}
+		d.init = true
+	}
+	d.total += uint64(len(p))
+	if d.n > 0 {
+		k := copy(d.mem[d.n:], p)
+		d.n += k
+		p = p[k:]
+		if d.n < 32 {
+			return
+		}
+		d.stripe(d.mem[:])
+		d.n = 0
+	}
+	for ; len(p) >= 32; p = p[32:] {
+		d.stripe(p)
+	}
+	d.n = copy(d.mem[:], p)
+}
+
+func (d *xxh64) stripe(p []byte) {
+	for i := range d.v {
+		d.v[i] = xxhRound(d.v[i], binary.LittleEndian.Uint64(p[8*i:]))
+	}
+}
+
+func (d *xxh64) sum() uint64 {
+	var h uint64
+	if d.total >= 32 {
+		v := d.v
+		h = bits.RotateLeft64(v[0], 1) + bits.RotateLeft64(v[1], 7) + bits.RotateLeft64(v[2], 12) + bits.RotateLeft64(v[3], 18)
+		for _, x := range v {
+			h = (h^xxhRound(0, x))*xxhPrime1 + xxhPrime4
+		}
+	} else {
+		h = xxhPrime5
+	}
+	h += d.total
+
+	p := d.mem[:d.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h = bits.RotateLeft64(h^xxhRound(0, binary.LittleEndian.Uint64(p)), 27)*xxhPrime1 + xxhPrime4
+	}
+	if len(p) >= 4 {
+		h = bits.RotateLeft64(h^uint64(binary.LittleEndian.Uint32(p))*xxhPrime1, 23)*xxhPrime2 + xxhPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h = bits.RotateLeft64(h^uint64(b)*xxhPrime5, 11) * xxhPrime1
+	}
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	return h
+}
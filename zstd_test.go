@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Three items compressed by zstd -19 with checksum, followed by a skippable frame
+const zstdFixture = "28b52ffd046875020012840e11907d64903c90c47bffe7751e2ab2fbb30e60818e5aa5b62033c875ad8a4e4e48df7264fa8330213d7e0eb6fd413c9e0d7f48df249bcf43002a06002f07404753f54b9541c0411e0f5506f54b1fc6" +
+	"502a4d180400000001020304"
+
+const zstdFixtureText = `[
+  {"name": "tent", "weight": 2, "value": 30},
+  {"name": "pegs", "weight": 0.25, "value": 1},
+  {"name": "rope", "weight": 0.5, "value": 5}
+]
+`
+
+func TestZstdFixture(t *testing.T) {
+	data, err := hex.DecodeString(zstdFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := decompress(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != zstdFixtureText {
+		t.Errorf("decompressed\n%q\nwant\n%q", got, zstdFixtureText)
+	}
+}
+
+func TestZstdCorrupted(t *testing.T) {
+	data, err := hex.DecodeString(zstdFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Truncated frames, flipped bits in the payload and checksum, all must fail
+	cases := map[string][]byte{
+		"truncated header": data[:6],
+		"truncated block":  data[:len(data)/2],
+		"no checksum":      data[:len(data)-16],
+	}
+	for _, i := range []int{10, 30, len(data) - 14} {
+		flipped := append([]byte(nil), data...)
+		flipped[i] ^= 0x10
+		cases[fmt.Sprintf("flipped byte %d", i)] = flipped
+	}
+	for name, input := range cases {
+		if _, err := io.ReadAll(newZstdReader(bytes.NewReader(input))); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// Comparing with the reference implementation when its command line tool is installed
+func TestZstdReference(t *testing.T) {
+	path, err := exec.LookPath("zstd")
+	if err != nil {
+		t.Skip("zstd command not found")
+	}
+	rng := rand.New(rand.NewSource(1))
+	var text strings.Builder
+	text.WriteString("name,weight,value\n")
+	for text.Len() < 700<<10 {
+		fmt.Fprintf(&text, "item%d,%.2f,%d\n", rng.Intn(5000), rng.Float64()*10, rng.Intn(100))
+	}
+	noise := make([]byte, 300<<10)
+	rng.Read(noise)
+	inputs := map[string][]byte{
+		"empty":  nil,
+		"byte":   []byte("x"),
+		"repeat": bytes.Repeat([]byte("ab"), 200<<10),
+		"csv":    []byte(text.String()),
+		"noise":  noise,
+		"mixed":  append(append([]byte(text.String()[:200<<10]), noise[:50<<10]...), text.String()[:300<<10]...),
+	}
+	compress := func(input []byte, args ...string) []byte {
+		cmd := exec.Command(path, append(args, "-q", "-c")...)
+		cmd.Stdin = bytes.NewReader(input)
+		compressed, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return compressed
+	}
+	for name, input := range inputs {
+		for _, args := range [][]string{{"-1"}, {"-3", "--no-check"}, {"-9"}, {"-19"}, {"--ultra", "-22", "--long=24"}} {
+			got, err := io.ReadAll(newZstdReader(bytes.NewReader(compress(input, args...))))
+			if err != nil {
+				t.Errorf("%s %v: %v", name, args, err)
+				continue
+			}
+			if !bytes.Equal(got, input) {
+				t.Errorf("%s %v: decompressed %d bytes differ from %d bytes", name, args, len(got), len(input))
+			}
+		}
+	}
+
+	// Concatenated frames are decompressed one after another
+	first, second := inputs["csv"][:1000], inputs["repeat"][:5000]
+	got, err := io.ReadAll(newZstdReader(bytes.NewReader(append(compress(first, "-5"), compress(second, "-5")...))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, append(append([]byte(nil), first...), second...)) {
+		t.Errorf("concatenated frames decompressed to %d bytes", len(got))
+	}
+}